@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
 	"time"
+	_ "time/tzdata" // Los horarios se evalúan en Europe/Madrid aunque la imagen no traiga zoneinfo
 
 	"veterimap-api/internal/auth"
 	"veterimap-api/internal/db"
+	"veterimap-api/internal/domain"
 	"veterimap-api/internal/handlers"
+	"veterimap-api/internal/pkg/mailer"
 	"veterimap-api/internal/services"
+	"veterimap-api/migrations"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -22,6 +27,16 @@ func main() {
 		log.Println("⚠️  No se encontró archivo .env, usando variables de entorno del sistema")
 	}
 
+	// Claves de firma de los JWT (JWT_PRIVATE_KEY_FILE, JWT_SECRET...; ver auth/keys.go)
+	if err := auth.LoadKeysFromEnv(); err != nil {
+		log.Fatalf("❌ Claves JWT inválidas: %v", err)
+	}
+
+	// Proxies de los que se acepta X-Forwarded-For (TRUSTED_PROXIES; ver handlers/client_ip.go)
+	if err := handlers.LoadTrustedProxiesFromEnv(); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
 	// 2. Conectar a la Base de Datos
 	// Usamos db.Conn que es el pool de conexiones pgx
 	if err := db.InitDB(); err != nil {
@@ -29,25 +44,64 @@ func main() {
 	}
 	defer db.CloseDB()
 
-	// 3. Ejecutar Seeder / Migraciones
-	// Esto asegura que las tablas y datos de prueba existan
-	log.Println("⏳ Ejecutando migración y seeder de datos...")
-	if err := db.SeedClinicas(); err != nil {
-		log.Printf("⚠️ Nota sobre el seeder: %v", err)
+	// Subcomando de mantenimiento: api migrate up | down [n] | status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
+	// 3. Migraciones pendientes (con advisory lock: si arrancan varias réplicas,
+	// solo una las aplica). Los datos se importan aparte con cmd/tools/importar.
+	migrator, err := db.NewMigrator(db.Conn, migrations.FS)
+	if err != nil {
+		log.Fatalf("❌ Migraciones inválidas: %v", err)
 	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		log.Fatalf("❌ Error aplicando migraciones: %v", err)
+	}
+
+	// Correo saliente (MAIL_BACKEND=smtp|dev|file|stdout; ver pkg/mailer). Close
+	// espera a que se entreguen los correos encolados.
+	mail, err := mailer.NewFromEnv()
+	if err != nil {
+		log.Fatalf("❌ Configuración de correo inválida: %v", err)
+	}
+	defer mail.Close()
 
 	// 4. Inicializar Repositorios
 	// Inyectamos db.Conn (pool pgx) en los repositorios
 	userRepo := db.NewPostgresUserRepository(db.Conn)
 	profileRepo := db.NewPostgresProfileRepository(db.Conn)
+	claimRepo := db.NewPostgresClaimRepository(db.Conn)
+	reviewRepo := db.NewPostgresReviewRepository(db.Conn)
+	revisionRepo := db.NewPostgresRevisionRepository(db.Conn)
+	adminRepo := db.NewPostgresAdminRepository(db.Conn)
+	duplicateRepo := db.NewPostgresDuplicateRepository(db.Conn)
+	sessionRepo := db.NewPostgresSessionRepository(db.Conn)
+	passwordRepo := db.NewPostgresPasswordRepository(db.Conn)
+	verificationRepo := db.NewPostgresVerificationRepository(db.Conn)
 
 	// 5. Inicializar Servicios
-	authService := services.NewAuthService(userRepo)
+	sessionService := services.NewSessionService(sessionRepo)
+	authService := services.NewAuthService(userRepo, revisionRepo, sessionService, verificationRepo, mail)
+	passwordService := services.NewPasswordService(userRepo, passwordRepo, sessionRepo, mail)
+	claimService := services.NewClaimService(claimRepo, profileRepo, userRepo, mail)
+	reviewService := services.NewReviewService(reviewRepo, profileRepo)
+	moderationService := services.NewModerationService(revisionRepo, profileRepo)
+	adminService := services.NewAdminService(adminRepo, verificationRepo, mail)
+	duplicateService := services.NewDuplicateService(duplicateRepo)
 
 	// 6. Inicializar Handlers
 	authHandler := handlers.NewAuthHandler(authService)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	passwordHandler := handlers.NewPasswordHandler(passwordService)
 	profileHandler := handlers.NewProfileHandler(profileRepo)
-	userHandler := handlers.NewUserHandler(userRepo, profileRepo)
+	userHandler := handlers.NewUserHandler(userRepo, profileRepo, mail)
+	claimHandler := handlers.NewClaimHandler(claimService)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+	moderationHandler := handlers.NewModerationHandler(moderationService)
+	adminHandler := handlers.NewAdminHandler(adminService)
+	duplicateHandler := handlers.NewDuplicateHandler(duplicateService)
 
 	// 7. Configurar el Router (Chi)
 	r := chi.NewRouter()
@@ -64,23 +118,37 @@ func main() {
 		w.Write([]byte("OK"))
 	})
 
+	// Claves públicas para que otros servicios validen nuestros tokens
+	r.Get("/.well-known/jwks.json", handlers.JWKS)
+
 	r.Route("/api/auth", func(r chi.Router) {
 		r.Post("/register", authHandler.Register)
 		r.Post("/login", authHandler.Login)
 		r.Post("/verify", authHandler.Verify)
+		r.Post("/verify/resend", authHandler.ResendVerification) // Código nuevo, con límite de envíos
+		r.Post("/refresh", sessionHandler.Refresh)               // Rota el refresh token
+		r.Post("/logout", sessionHandler.Logout)
+		r.Post("/password/forgot", passwordHandler.Forgot) // Envía el enlace de recuperación
+		r.Post("/password/reset", passwordHandler.Reset)
 	})
 
 	r.Route("/api/profiles", func(r chi.Router) {
-		r.Get("/", profileHandler.List)            // Marketplace / Lista general
-		r.Get("/detail", profileHandler.GetDetail) // Ficha individual
-		r.Get("/map", profileHandler.SearchMap)    // Endpoint clave para los pines del mapa
+		r.Get("/", profileHandler.List)                      // Marketplace / Lista general
+		r.Get("/detail", profileHandler.GetDetail)           // Ficha individual
+		r.Get("/map", profileHandler.SearchMap)              // Endpoint clave para los pines del mapa
+		r.Get("/suggest", profileHandler.Suggest)            // Autocompletado del buscador
+		r.Get("/tiles/{z}/{x}/{y}.mvt", profileHandler.Tile) // Teselas vectoriales cacheables
+		r.Get("/{slug}", profileHandler.GetBySlug)           // Ficha pública por slug (301 si es antiguo)
+		r.Get("/{slug}/reviews", reviewHandler.List)         // Reseñas publicadas, paginadas
 	})
 
 	// --- RUTAS PRIVADAS (Requieren JWT) ---
 	r.Group(func(r chi.Router) {
 		r.Use(auth.JWTMiddleware())
+		r.Use(auth.RejectStaleTokens(passwordRepo)) // Tokens anteriores a un cambio de contraseña
 
 		r.Get("/api/me", authHandler.MeHandler)
+		r.Post("/api/auth/logout-all", sessionHandler.LogoutAll)
 
 		// 1. Grupo de Usuario (TODO lo que cuelga de /api/users/me)
 		r.Route("/api/users/me", func(r chi.Router) {
@@ -109,6 +177,13 @@ func main() {
 			r.Patch("/appointments/reschedule", userHandler.Reschedule)
 
 			r.Get("/pets/owner/{ownerID}", userHandler.GetPetsByOwner)
+
+			// Sesiones abiertas: listado por dispositivo y cierre de una concreta
+			r.Get("/sessions", sessionHandler.List)
+			r.Delete("/sessions/{sessionID}", sessionHandler.Revoke)
+
+			// Cambio de contraseña con la sesión iniciada (cierra el resto de sesiones)
+			r.Post("/password", passwordHandler.Change)
 		})
 
 		// 2. Grupo de Historial Médico
@@ -119,6 +194,58 @@ func main() {
 			// Esta ruta permite que David cree una nueva entrada (POST)
 			r.Post("/", userHandler.AddMedicalHistory)
 		})
+
+		// 3. Reclamación de fichas importadas (solo profesionales)
+		r.Route("/api/claims", func(r chi.Router) {
+			r.Use(auth.AuthorizeRole(domain.RoleProfessional))
+			r.Get("/", claimHandler.ListMine)
+			r.Post("/", claimHandler.Request)
+			r.Post("/{claimID}/verify", claimHandler.Verify)
+		})
+
+		// 4. Panel de administración
+		r.Route("/api/admin", func(r chi.Router) {
+			r.Use(auth.AuthorizeRole(domain.RoleAdmin))
+
+			// Cuentas: búsqueda, suscripción y reenvío del código de verificación
+			r.Get("/users", adminHandler.ListUsers)
+			r.Get("/users/{userID}", adminHandler.GetUser)
+			r.Patch("/users/{userID}/subscription", adminHandler.UpdateSubscription)
+			r.Post("/users/{userID}/resend-verification", adminHandler.ResendVerification)
+
+			// Fichas profesionales (incluidas las desactivadas)
+			r.Get("/entities", adminHandler.ListEntities)
+			r.Patch("/entities/{entityID}/active", adminHandler.SetEntityActive)
+			r.Patch("/entities/{entityID}/status", adminHandler.SetEntityStatus)
+
+			// Fichas duplicadas: cola de candidatas, descarte y fusión
+			r.Get("/duplicates", duplicateHandler.List)
+			r.Post("/duplicates/dismiss", duplicateHandler.Dismiss)
+			r.Post("/duplicates/merge", duplicateHandler.Merge)
+
+			// Reclamaciones de fichas importadas
+			r.Get("/claims", claimHandler.AdminList)
+			r.Post("/claims/{claimID}/approve", claimHandler.AdminApprove)
+			r.Post("/claims/{claimID}/reject", claimHandler.AdminReject)
+
+			// Reseñas ocultas por reportes: restaurar o confirmar la ocultación
+			r.Get("/reviews", reviewHandler.AdminList)
+			r.Post("/reviews/{reviewID}/restore", reviewHandler.AdminRestore)
+			r.Post("/reviews/{reviewID}/hide", reviewHandler.AdminHide)
+
+			// Cambios en fichas pendientes de moderación
+			r.Get("/revisions", moderationHandler.List)
+			r.Get("/revisions/{revisionID}", moderationHandler.Get)
+			r.Post("/revisions/{revisionID}/approve", moderationHandler.Approve)
+			r.Post("/revisions/{revisionID}/reject", moderationHandler.Reject)
+		})
+
+		// 5. Reseñas: valorar (dueños), responder (titular de la ficha) y reportar (cualquiera)
+		r.Route("/api/reviews", func(r chi.Router) {
+			r.With(auth.AuthorizeRole(domain.RolePetOwner)).Post("/", reviewHandler.Create)
+			r.With(auth.AuthorizeRole(domain.RoleProfessional)).Post("/{reviewID}/reply", reviewHandler.Reply)
+			r.Post("/{reviewID}/report", reviewHandler.Report)
+		})
 	})
 
 	// 8. Arrancar el Servidor
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"veterimap-api/internal/db"
+	"veterimap-api/migrations"
+)
+
+const migrateUsage = "uso: api migrate up | down [n] | status"
+
+// runMigrate atiende el subcomando "migrate" (up, down [n], status) y termina
+func runMigrate(args []string) {
+	migrator, err := db.NewMigrator(db.Conn, migrations.FS)
+	if err != nil {
+		log.Fatalf("❌ Migraciones inválidas: %v", err)
+	}
+	ctx := context.Background()
+
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+	switch args[0] {
+	case "up":
+		done, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		log.Printf("✅ %d migraciones aplicadas", len(done))
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatal(migrateUsage)
+			}
+		}
+		done, err := migrator.Down(ctx, steps)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		log.Printf("✅ %d migraciones deshechas", len(done))
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		for _, s := range status {
+			state := "pendiente"
+			if s.AppliedAt != nil {
+				state = "aplicada " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			switch {
+			case s.Missing:
+				state += " (no está en el binario)"
+			case s.Changed:
+				state += " (¡el fichero ha cambiado!)"
+			}
+			fmt.Fprintf(os.Stdout, "%02d_%-28s %s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
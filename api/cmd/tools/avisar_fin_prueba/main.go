@@ -0,0 +1,121 @@
+// avisar_fin_prueba envía un correo a los profesionales cuyo periodo de prueba
+// termina en los próximos días. Pensado para ejecutarse una vez al día (cron):
+//
+//	go run ./cmd/tools/avisar_fin_prueba --days 3 --dry-run
+//
+// Cada cuenta se avisa una sola vez por fecha de fin (users.trial_notice_sent_at).
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"veterimap-api/internal/db"
+	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/mailer"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+)
+
+type pendingNotice struct {
+	id          uuid.UUID
+	email       string
+	name        string
+	locale      string
+	trialEndsAt time.Time
+}
+
+func main() {
+	days := flag.Int("days", 3, "avisa de las pruebas que terminan en este número de días")
+	dryRun := flag.Bool("dry-run", false, "muestra a quién se avisaría sin enviar nada")
+	flag.Parse()
+
+	// 1. Cargar entorno (buscando en varios niveles)
+	_ = godotenv.Load(".env", "../.env", "../../.env")
+
+	if os.Getenv("DB_URL") == "" {
+		log.Fatal("❌ ERROR: No se detectó la variable DB_URL. Revisa el archivo .env")
+	}
+
+	mail, err := mailer.NewFromEnv()
+	if err != nil {
+		log.Fatalf("❌ Configuración de correo inválida: %v", err)
+	}
+	defer mail.Close()
+
+	// 2. Conectar a la DB
+	if err := db.InitDB(); err != nil {
+		log.Fatalf("❌ Error de conexión: %v", err)
+	}
+	defer db.CloseDB()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// 3. Profesionales con la prueba a punto de terminar y sin avisar para esa fecha
+	rows, err := db.Conn.Query(ctx, `
+		SELECT id, email, COALESCE(name, ''), locale, trial_ends_at
+		FROM users
+		WHERE role = $1
+		  AND trial_ends_at > NOW()
+		  AND trial_ends_at <= NOW() + make_interval(days => $2)
+		  AND (trial_notice_sent_at IS NULL OR trial_notice_sent_at < trial_ends_at - make_interval(days => $2))
+		ORDER BY trial_ends_at`, string(domain.RoleProfessional), *days)
+	if err != nil {
+		log.Fatalf("❌ Error al consultar: %v", err)
+	}
+	var pending []pendingNotice
+	for rows.Next() {
+		var p pendingNotice
+		if err := rows.Scan(&p.id, &p.email, &p.name, &p.locale, &p.trialEndsAt); err != nil {
+			log.Fatalf("❌ Error scan: %v", err)
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Fatalf("❌ Error al consultar: %v", err)
+	}
+
+	log.Printf("⏳ %d pruebas terminan en los próximos %d días", len(pending), *days)
+
+	// 4. Avisar y marcar. Deliver es síncrono: solo se marca lo que se ha entregado.
+	failed := 0
+	for _, p := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		endsAt := p.trialEndsAt.In(domain.ScheduleLocation()).Format("02/01/2006")
+		if *dryRun {
+			log.Printf("📝 [dry-run] %s (%s) termina el %s", p.email, p.id, endsAt)
+			continue
+		}
+
+		err := mail.Deliver(ctx, p.email, p.locale, domain.EmailTrialEnding, map[string]interface{}{
+			"Name":        p.name,
+			"TrialEndsAt": endsAt,
+		})
+		if err != nil {
+			log.Printf("❌ %s: %v", p.email, err)
+			failed++
+			continue
+		}
+		if _, err := db.Conn.Exec(ctx, `UPDATE users SET trial_notice_sent_at = NOW() WHERE id = $1`, p.id); err != nil {
+			log.Printf("❌ Aviso enviado a %s pero no se pudo marcar: %v", p.email, err)
+			failed++
+			continue
+		}
+		log.Printf("✅ Aviso enviado a %s (termina el %s)", p.email, endsAt)
+	}
+
+	if failed > 0 {
+		mail.Close()
+		db.CloseDB()
+		os.Exit(1)
+	}
+}
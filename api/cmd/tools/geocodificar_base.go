@@ -28,13 +28,15 @@ func main() {
 
 	log.Println("🛰️ Iniciando geocodificación en professional_entities...")
 
-	// 3. Buscar entidades sin coordenadas
-	// Filtramos por latitude '0' o NULL
+	// 3. Buscar entidades con alguna dirección sin coordenadas
+	// professional_locations guarda como NULL las coordenadas 0 o ausentes de cualquier dirección
 	query := `
-		SELECT id, profile_data 
-		FROM professional_entities 
-		WHERE profile_data->'addresses'->0->>'latitude' = '0' 
-		   OR profile_data->'addresses'->0->>'latitude' IS NULL
+		SELECT e.id, e.profile_data 
+		FROM professional_entities e
+		WHERE EXISTS (
+			SELECT 1 FROM professional_locations l
+			WHERE l.entity_id = e.id AND (l.latitude IS NULL OR l.longitude IS NULL)
+		)
 		LIMIT 100` // Limitamos para evitar bloqueos largos en pruebas
 
 	rows, err := db.Conn.Query(context.Background(), query)
@@ -65,42 +67,55 @@ func main() {
 			continue
 		}
 
-		firstAddr, ok := addresses[0].(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		// Extraer datos para geocodificar con valores por defecto
-		addr, _ := firstAddr["full_address"].(string)
-		city, _ := firstAddr["city"].(string)
-
-		if addr == "" || city == "" {
-			log.Printf("⚠️ ID %s tiene dirección o ciudad vacía", id)
-			continue
+		// Recorremos todas las direcciones, no solo la primera
+		updated := 0
+		for i, rawAddr := range addresses {
+			address, ok := rawAddr.(map[string]interface{})
+			if !ok || hasCoordinates(address) {
+				continue
+			}
+
+			// Extraer datos para geocodificar con valores por defecto
+			addr, _ := address["full_address"].(string)
+			city, _ := address["city"].(string)
+
+			if addr == "" || city == "" {
+				log.Printf("⚠️ ID %s (dirección %d) tiene dirección o ciudad vacía", id, i)
+				continue
+			}
+
+			fullSearch := addr + ", " + city
+			log.Printf("📍 Buscando: %s", fullSearch)
+
+			// 4. Obtener coordenadas de la API
+			lat, lon, err := geo.GetCoordinates(fullSearch)
+			if err != nil {
+				log.Printf("❌ Error en API para %s (dirección %d): %v", id, i, err)
+				time.Sleep(1 * time.Second)
+				continue
+			}
+
+			// 5. Actualizar el mapa interno (se guardan como float64 automáticamente)
+			address["latitude"] = lat
+			address["longitude"] = lon
+			updated++
+			log.Printf("📌 %s [%f, %f]", city, lat, lon)
+
+			// Respetar Rate Limit (1.2 segundos entre peticiones)
+			time.Sleep(1200 * time.Millisecond)
 		}
 
-		fullSearch := addr + ", " + city
-		log.Printf("📍 Buscando: %s", fullSearch)
-
-		// 4. Obtener coordenadas de la API
-		lat, lon, err := geo.GetCoordinates(fullSearch)
-		if err != nil {
-			log.Printf("❌ Error en API para %s: %v", id, err)
-			time.Sleep(1 * time.Second)
+		if updated == 0 {
 			continue
 		}
 
-		// 5. Actualizar el mapa interno (se guardan como float64 automáticamente)
-		firstAddr["latitude"] = lat
-		firstAddr["longitude"] = lon
-		
 		newJSON, err := json.Marshal(profileData)
 		if err != nil {
 			log.Printf("❌ Error marshal final: %v", err)
 			continue
 		}
 
-		// 6. Guardar en la base de datos
+		// 6. Guardar en la base de datos (el trigger actualiza professional_locations)
 		_, updateErr := db.Conn.Exec(context.Background(),
 			"UPDATE professional_entities SET profile_data = $1 WHERE id = $2",
 			newJSON, id)
@@ -108,12 +123,16 @@ func main() {
 		if updateErr != nil {
 			log.Printf("❌ Error DB al actualizar ID %s: %v", id, updateErr)
 		} else {
-			log.Printf("✅ Actualizado: %s [%f, %f]", city, lat, lon)
+			log.Printf("✅ Actualizado: %s (%d direcciones)", id, updated)
 		}
-
-		// Respetar Rate Limit (1.2 segundos entre peticiones)
-		time.Sleep(1200 * time.Millisecond)
 	}
 
 	log.Println("🏁 Proceso de geocodificación finalizado.")
-}
\ No newline at end of file
+}
+
+// hasCoordinates indica si la dirección ya tiene latitud y longitud distintas de 0
+func hasCoordinates(address map[string]interface{}) bool {
+	lat, okLat := address["latitude"].(float64)
+	lng, okLng := address["longitude"].(float64)
+	return okLat && okLng && lat != 0 && lng != 0
+}
@@ -0,0 +1,69 @@
+// importar carga fichas PROSPECT desde un volcado: clinicas.json, o un CSV o
+// GeoJSON de colegios y socios con un mapeo de columnas (ver db.ImportMapping).
+//
+//	go run ./cmd/tools/importar --file clinicas.json --source google-places-2025-10 --dry-run
+//	go run ./cmd/tools/importar --file colegio.csv --mapping colegio.mapping.json --source colegio-madrid
+//
+// Las fichas reclamadas, verificadas o con titular nunca se sobrescriben.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	"veterimap-api/internal/db"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	file := flag.String("file", "clinicas.json", "volcado a importar (.json, .csv o .geojson)")
+	format := flag.String("format", "", "json, csv o geojson (por defecto, según la extensión)")
+	mappingFile := flag.String("mapping", "", "mapeo de columnas en JSON para CSV/GeoJSON")
+	dryRun := flag.Bool("dry-run", false, "clasifica y muestra el informe sin escribir en la base de datos")
+	onlyNew := flag.Bool("only-new", false, "solo inserta fichas nuevas; las existentes no se tocan")
+	source := flag.String("source", "", "procedencia de los datos (se guarda en import_source)")
+	flag.Parse()
+
+	mapping, err := db.LoadImportMapping(*mappingFile)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	// 1. Cargar entorno (buscando en varios niveles)
+	_ = godotenv.Load(".env", "../.env", "../../.env")
+
+	if os.Getenv("DB_URL") == "" {
+		log.Fatal("❌ ERROR: No se detectó la variable DB_URL. Revisa el archivo .env")
+	}
+
+	// 2. Conectar a la DB
+	if err := db.InitDB(); err != nil {
+		log.Fatalf("❌ Error de conexión: %v", err)
+	}
+	defer db.CloseDB()
+
+	// Ctrl+C detiene la importación entre registros y muestra el informe parcial
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// 3. Importar
+	report, err := db.ImportFile(ctx, *file, *format, mapping, db.ImportOptions{
+		Source:  *source,
+		DryRun:  *dryRun,
+		OnlyNew: *onlyNew,
+	})
+	report.Log()
+	if err != nil {
+		log.Printf("❌ Importación interrumpida: %v", err)
+		db.CloseDB()
+		os.Exit(1)
+	}
+	if len(report.Failed) > 0 {
+		db.CloseDB()
+		os.Exit(1)
+	}
+}
@@ -15,8 +15,6 @@ import (
 type contextKey string
 const ClaimsContextKey contextKey = "user_claims"
 
-const jwtSecret = "tu-secreto-super-seguro-aqui"
-
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(bytes), err
@@ -28,23 +26,31 @@ func CheckPasswordHash(password, hash string) bool {
 }
 
 type Claims struct {
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
+	UserID    string `json:"user_id"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"` // Sesión (refresh token) que emitió el token
 	jwt.RegisteredClaims
 }
 
+// AccessTokenTTL es la vida del access token; se renueva con el refresh token
+// de la sesión (JWT_ACCESS_TTL para cambiarla)
+var AccessTokenTTL = 15 * time.Minute
 
-func GenerateJWT(userID, role string) (string, error) {
+func GenerateJWT(userID, role, sessionID string) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Role:   role,
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(jwtSecret))
+	ks, err := currentKeys()
+	if err != nil {
+		return "", err
+	}
+	return ks.sign(claims)
 }
 
 // GetClaims extrae los claims del contexto de la petición
@@ -80,10 +86,13 @@ func AuthorizeRole(allowedRoles ...domain.Role) func(http.Handler) http.Handler
 	}
 }
 
+// ValidateJWT acepta tokens firmados con cualquiera de las claves activas (ver keys.go)
 func ValidateJWT(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(jwtSecret), nil
-	})
+	ks, err := currentKeys()
+	if err != nil {
+		return nil, err
+	}
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, ks.keyFunc, jwt.WithValidMethods(ks.algorithms()))
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,368 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Configuración de las claves de firma (variables de entorno):
+//
+//	JWT_PRIVATE_KEY_FILE  PEM con la clave de firma actual (RSA → RS256, Ed25519 → EdDSA)
+//	JWT_KEY_ID            kid de esa clave (por defecto, su huella RFC 7638)
+//	JWT_PUBLIC_KEY_FILES  PEMs de claves anteriores que se siguen aceptando, separados
+//	                      por comas; admite "kid=ruta" para conservar el kid original
+//	JWT_SECRET            secreto HS256: firma solo si no hay clave privada; si la hay,
+//	                      solo verifica (migrar a RS256/EdDSA no cierra sesiones)
+//	JWT_PREVIOUS_SECRETS  secretos HS256 anteriores, solo para verificar
+//	JWT_ACCESS_TTL        vida del access token (por defecto 15m)
+//	APP_ENV               con "development" se admite arrancar sin clave (ver LoadKeysFromEnv)
+//
+// Rotar = mover la clave actual a JWT_PUBLIC_KEY_FILES, poner la nueva y, pasado
+// AccessTokenTTL (JWT_ACCESS_TTL, 15 min por defecto), retirar la anterior: ya no
+// queda ningún access token vivo firmado con ella. Los refresh tokens son opacos y
+// no dependen de estas claves.
+
+var (
+	ErrNoSigningKey = errors.New("no hay clave de firma JWT configurada")
+	ErrUnknownKey   = errors.New("el token está firmado con una clave desconocida")
+)
+
+// SigningKey es una clave de firma o solo de verificación
+type SigningKey struct {
+	ID        string
+	Algorithm string           // RS256, EdDSA o HS256
+	Private   interface{}      // *rsa.PrivateKey, ed25519.PrivateKey o []byte (HS256); nil = solo verificación
+	Public    crypto.PublicKey // nil en HS256: el secreto nunca se publica
+	verify    interface{}      // Lo que espera jwt para verificar
+}
+
+// KeySet es la clave con la que se firma más las que se aceptan al verificar
+type KeySet struct {
+	signing *SigningKey
+	keys    []*SigningKey
+}
+
+var (
+	keysMu sync.RWMutex
+	keys   *KeySet
+)
+
+// SetKeySet sustituye las claves activas (al arrancar o al rotar en caliente)
+func SetKeySet(ks *KeySet) {
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	keys = ks
+}
+
+func currentKeys() (*KeySet, error) {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	if keys == nil || keys.signing == nil {
+		return nil, ErrNoSigningKey
+	}
+	return keys, nil
+}
+
+// LoadKeysFromEnv construye el KeySet con la configuración del entorno y lo activa.
+// Sin clave de firma devuelve ErrNoSigningKey: con una efímera cada reinicio (o cada
+// réplica) invalidaría los tokens de las demás. Solo con APP_ENV=development genera
+// una clave Ed25519 efímera, y los tokens no sobreviven a un reinicio.
+func LoadKeysFromEnv() error {
+	if v := os.Getenv("JWT_ACCESS_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl <= 0 {
+			return fmt.Errorf("JWT_ACCESS_TTL no válido: %q", v)
+		}
+		AccessTokenTTL = ttl
+	}
+
+	ks := &KeySet{}
+
+	if path := os.Getenv("JWT_PRIVATE_KEY_FILE"); path != "" {
+		key, err := loadPEMKey(path, os.Getenv("JWT_KEY_ID"))
+		if err != nil {
+			return err
+		}
+		if key.Private == nil {
+			return fmt.Errorf("%s no contiene una clave privada", path)
+		}
+		ks.add(key, true)
+	}
+
+	for _, entry := range splitList(os.Getenv("JWT_PUBLIC_KEY_FILES")) {
+		kid, path := "", entry
+		if i := strings.Index(entry, "="); i > 0 {
+			kid, path = entry[:i], entry[i+1:]
+		}
+		key, err := loadPEMKey(path, kid)
+		if err != nil {
+			return err
+		}
+		key.Private = nil // Las anteriores solo verifican
+		ks.add(key, false)
+	}
+
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		ks.add(NewHMACKey(secret), ks.signing == nil)
+	}
+	for _, secret := range splitList(os.Getenv("JWT_PREVIOUS_SECRETS")) {
+		key := NewHMACKey(secret)
+		key.Private = nil
+		ks.add(key, false)
+	}
+
+	if ks.signing == nil {
+		if !strings.EqualFold(strings.TrimSpace(os.Getenv("APP_ENV")), "development") {
+			return fmt.Errorf("%w: define JWT_PRIVATE_KEY_FILE o JWT_SECRET (o APP_ENV=development para una clave efímera)", ErrNoSigningKey)
+		}
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return err
+		}
+		key, err := NewSigningKey(priv, "")
+		if err != nil {
+			return err
+		}
+		ks.add(key, true)
+		log.Println("⚠️  JWT_PRIVATE_KEY_FILE/JWT_SECRET no configurados: clave Ed25519 efímera, las sesiones caducan al reiniciar")
+	}
+
+	SetKeySet(ks)
+	log.Printf("🔑 JWT: firmando con %s (kid %s), %d claves aceptadas", ks.signing.Algorithm, ks.signing.ID, len(ks.keys))
+	return nil
+}
+
+// NewKeySet crea un KeySet que firma con signing y acepta además verifyOnly
+func NewKeySet(signing *SigningKey, verifyOnly ...*SigningKey) *KeySet {
+	ks := &KeySet{}
+	ks.add(signing, true)
+	for _, k := range verifyOnly {
+		ks.add(k, false)
+	}
+	return ks
+}
+
+// add registra la clave; si el kid ya existe, la nueva sustituye a la anterior
+func (ks *KeySet) add(key *SigningKey, signing bool) {
+	for i, k := range ks.keys {
+		if k.ID == key.ID {
+			ks.keys = append(ks.keys[:i], ks.keys[i+1:]...)
+			break
+		}
+	}
+	ks.keys = append(ks.keys, key)
+	if signing {
+		ks.signing = key
+	}
+}
+
+// NewSigningKey envuelve una clave RSA o Ed25519 (privada o pública). Sin kid se
+// usa la huella RFC 7638, estable entre despliegues.
+func NewSigningKey(key interface{}, kid string) (*SigningKey, error) {
+	k := &SigningKey{ID: kid}
+	switch t := key.(type) {
+	case *rsa.PrivateKey:
+		k.Algorithm, k.Private, k.Public = jwt.SigningMethodRS256.Alg(), t, &t.PublicKey
+	case *rsa.PublicKey:
+		k.Algorithm, k.Public = jwt.SigningMethodRS256.Alg(), t
+	case ed25519.PrivateKey:
+		k.Algorithm, k.Private, k.Public = jwt.SigningMethodEdDSA.Alg(), t, t.Public()
+	case ed25519.PublicKey:
+		k.Algorithm, k.Public = jwt.SigningMethodEdDSA.Alg(), t
+	default:
+		return nil, fmt.Errorf("tipo de clave no soportado: %T (usa RSA o Ed25519)", key)
+	}
+	if rsaKey, ok := k.Public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return nil, errors.New("las claves RSA deben tener al menos 2048 bits")
+	}
+
+	k.verify = k.Public
+	if k.ID == "" {
+		k.ID = thumbprint(k.jwk())
+	}
+	return k, nil
+}
+
+// NewHMACKey crea una clave HS256. El kid se deriva del secreto sin revelarlo.
+func NewHMACKey(secret string) *SigningKey {
+	sum := sha256.Sum256([]byte(secret))
+	return &SigningKey{
+		ID:        "hs256-" + hex.EncodeToString(sum[:8]),
+		Algorithm: jwt.SigningMethodHS256.Alg(),
+		Private:   []byte(secret),
+		verify:    []byte(secret),
+	}
+}
+
+// loadPEMKey lee una clave PEM: PKCS#8, PKCS#1 (RSA) o PKIX (pública)
+func loadPEMKey(path, kid string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer la clave %s: %v", path, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s no es un fichero PEM", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: bloque PEM %q no soportado", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	k, err := NewSigningKey(key, kid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return k, nil
+}
+
+// sign firma los claims con la clave actual y añade su kid a la cabecera
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(ks.signing.Algorithm), claims)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.Private)
+}
+
+// keyFunc elige la clave por kid. El algoritmo tiene que ser el de la clave, para
+// que nadie pueda firmar con HS256 usando una clave pública como secreto. Los
+// tokens sin kid (anteriores a la rotación) se prueban con todas las del algoritmo.
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	alg := token.Method.Alg()
+	if kid, ok := token.Header["kid"].(string); ok {
+		for _, k := range ks.keys {
+			if k.ID == kid && k.Algorithm == alg {
+				return k.verify, nil
+			}
+		}
+		return nil, ErrUnknownKey
+	}
+
+	var set jwt.VerificationKeySet
+	for _, k := range ks.keys {
+		if k.Algorithm == alg {
+			set.Keys = append(set.Keys, k.verify)
+		}
+	}
+	if len(set.Keys) == 0 {
+		return nil, ErrUnknownKey
+	}
+	return set, nil
+}
+
+func (ks *KeySet) algorithms() []string {
+	seen := map[string]bool{}
+	var algs []string
+	for _, k := range ks.keys {
+		if !seen[k.Algorithm] {
+			seen[k.Algorithm] = true
+			algs = append(algs, k.Algorithm)
+		}
+	}
+	return algs
+}
+
+// JWK es una clave pública en formato RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"` // OKP
+	X   string `json:"x,omitempty"`   // OKP
+	N   string `json:"n,omitempty"`   // RSA
+	E   string `json:"e,omitempty"`   // RSA
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS publica las claves públicas aceptadas (las HS256 nunca se publican) para
+// que otros servicios validen los tokens sin conocer ningún secreto
+func JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	ks, err := currentKeys()
+	if err != nil {
+		return set
+	}
+	for _, k := range ks.keys {
+		if k.Public != nil {
+			set.Keys = append(set.Keys, k.jwk())
+		}
+	}
+	return set
+}
+
+func (k *SigningKey) jwk() JWK {
+	b64 := base64.RawURLEncoding.EncodeToString
+	j := JWK{Use: "sig", Alg: k.Algorithm, Kid: k.ID}
+	switch pub := k.Public.(type) {
+	case *rsa.PublicKey:
+		j.Kty, j.N, j.E = "RSA", b64(pub.N.Bytes()), b64(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		j.Kty, j.Crv, j.X = "OKP", "Ed25519", b64(pub)
+	}
+	return j
+}
+
+// thumbprint calcula la huella RFC 7638: SHA-256 de los miembros obligatorios en orden
+func thumbprint(j JWK) string {
+	var members interface{}
+	if j.Kty == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{j.E, j.Kty, j.N}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{j.Crv, j.Kty, j.X}
+	}
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -3,8 +3,11 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 	"veterimap-api/internal/domain"
 
 	"github.com/google/uuid"
@@ -43,7 +46,44 @@ func JWTMiddleware() func(http.Handler) http.Handler {
 	}
 }
 
+// RejectStaleTokens va detrás de JWTMiddleware: rechaza los access tokens emitidos
+// antes del último cambio o recuperación de contraseña, que si no seguirían valiendo
+// hasta caducar (AccessTokenTTL). El cliente refresca con su sesión, si sigue abierta.
+func RejectStaleTokens(repo domain.PasswordRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := GetClaims(r.Context())
+			if !ok {
+				http.Error(w, "No autorizado", http.StatusUnauthorized)
+				return
+			}
+			userID, err := uuid.Parse(claims.UserID)
+			if err != nil || claims.IssuedAt == nil {
+				http.Error(w, "Token inválido o expirado", http.StatusUnauthorized)
+				return
+			}
+
+			changedAt, err := repo.PasswordChangedAt(r.Context(), userID)
+			if errors.Is(err, domain.ErrUserNotFound) {
+				http.Error(w, "Token inválido o expirado", http.StatusUnauthorized)
+				return
+			}
+			if err != nil {
+				log.Printf("❌ No se pudo comprobar el cambio de contraseña de %s: %v", userID, err)
+				http.Error(w, "Error interno", http.StatusInternalServerError)
+				return
+			}
+			// iat va en segundos: se compara con el cambio truncado para no rechazar el
+			// token que la sesión actual obtiene justo después de cambiarla
+			if changedAt != nil && claims.IssuedAt.Time.Before(changedAt.Truncate(time.Second)) {
+				http.Error(w, "Token inválido o expirado", http.StatusUnauthorized)
+				return
+			}
 
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
 func CORS() func(http.Handler) http.Handler {
     return func(next http.Handler) http.Handler {
@@ -54,6 +94,7 @@ func CORS() func(http.Handler) http.Handler {
             w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
             
             w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+            w.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, X-Next-Cursor") // Paginación de ?format=geojson
 
             if r.Method == "OPTIONS" {
                 w.WriteHeader(http.StatusOK)
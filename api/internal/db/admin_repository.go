@@ -0,0 +1,210 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"veterimap-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresAdminRepository da acceso al panel de administración a todas las cuentas
+// y fichas, incluidas las desactivadas
+type PostgresAdminRepository struct {
+	Conn *pgxpool.Pool
+}
+
+func NewPostgresAdminRepository(db *pgxpool.Pool) *PostgresAdminRepository {
+	return &PostgresAdminRepository{Conn: db}
+}
+
+const adminUserColumns = `
+        u.id, u.email, u.name, u.role, u.is_verified, COALESCE(u.subscription_status, ''), u.trial_ends_at, u.locale, u.created_at,
+        e.id, e.name, e.status, e.is_active
+    FROM users u
+    LEFT JOIN professional_entities e ON e.user_id = u.id`
+
+func scanAdminUser(row pgx.Row) (*domain.AdminUser, error) {
+	var u domain.AdminUser
+	var entityID *uuid.UUID
+	var entityName, entityStatus *string
+	var entityActive *bool
+	err := row.Scan(
+		&u.ID, &u.Email, &u.Name, &u.Role, &u.IsVerified, &u.SubscriptionStatus, &u.TrialEndsAt, &u.Locale, &u.CreatedAt,
+		&entityID, &entityName, &entityStatus, &entityActive,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if entityID != nil {
+		u.Entity = &domain.EntityInfo{ID: *entityID, UserID: &u.ID, Name: *entityName, Status: *entityStatus, IsActive: *entityActive}
+	}
+	return &u, nil
+}
+
+func (r *PostgresAdminRepository) SearchUsers(ctx context.Context, f domain.UserFilter) (int, []domain.AdminUser, error) {
+	// El acumulador de las búsquedas de perfiles sirve igual para cualquier listado
+	q := &profileQuery{}
+	if f.Query != "" {
+		pattern := q.arg("%" + f.Query + "%")
+		q.where(fmt.Sprintf("(u.email ILIKE %s OR u.name ILIKE %s)", pattern, pattern))
+	}
+	if f.Role != "" {
+		q.where("u.role = " + q.arg(string(f.Role)))
+	}
+	if f.Verified != nil {
+		q.where("u.is_verified = " + q.arg(*f.Verified))
+	}
+	if f.SubscriptionStatus != "" {
+		q.where("u.subscription_status = " + q.arg(f.SubscriptionStatus))
+	}
+
+	var total int
+	err := r.Conn.QueryRow(ctx, `SELECT COUNT(*) FROM users u WHERE `+q.whereSQL(), q.args...).Scan(&total)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	limit, offset := q.arg(f.Limit), q.arg(f.Offset)
+	rows, err := r.Conn.Query(ctx, `
+        SELECT `+adminUserColumns+`
+        WHERE `+q.whereSQL()+`
+        ORDER BY u.created_at DESC, u.id
+        LIMIT `+limit+` OFFSET `+offset, q.args...)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	users := []domain.AdminUser{}
+	for rows.Next() {
+		u, err := scanAdminUser(rows)
+		if err != nil {
+			return 0, nil, err
+		}
+		users = append(users, *u)
+	}
+	return total, users, rows.Err()
+}
+
+func (r *PostgresAdminRepository) GetAdminUser(ctx context.Context, id uuid.UUID) (*domain.AdminUser, error) {
+	u, err := scanAdminUser(r.Conn.QueryRow(ctx, `SELECT `+adminUserColumns+` WHERE u.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	}
+	return u, err
+}
+
+func (r *PostgresAdminRepository) UpdateSubscription(ctx context.Context, id uuid.UUID, status string, trialEndsAt *time.Time) error {
+	tag, err := r.Conn.Exec(ctx, `
+        UPDATE users SET subscription_status = $2, trial_ends_at = $3
+        WHERE id = $1`, id, status, trialEndsAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// Ciudad de la ubicación principal (o de la primera) para distinguir fichas homónimas
+const entityInfoColumns = `
+        e.id, e.user_id, e.name, e.slug, e.entity_type, e.status, e.is_active,
+        COALESCE((SELECT l.city FROM professional_locations l
+                  WHERE l.entity_id = e.id
+                  ORDER BY l.is_main DESC, l.address_index LIMIT 1), ''),
+        e.updated_at
+    FROM professional_entities e`
+
+func scanEntityInfo(row pgx.Row) (*domain.EntityInfo, error) {
+	var e domain.EntityInfo
+	err := row.Scan(&e.ID, &e.UserID, &e.Name, &e.Slug, &e.EntityType, &e.Status, &e.IsActive, &e.City, &e.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *PostgresAdminRepository) SearchEntities(ctx context.Context, f domain.EntityFilter) (int, []domain.EntityInfo, error) {
+	q := &profileQuery{}
+	if f.Query != "" {
+		pattern := q.arg("%" + f.Query + "%")
+		q.where(fmt.Sprintf("(immutable_unaccent(e.name) ILIKE immutable_unaccent(%s) OR e.slug ILIKE %s)", pattern, pattern))
+	}
+	if f.Status != "" {
+		q.where("e.status = " + q.arg(f.Status))
+	}
+	if f.Active != nil {
+		q.where("e.is_active = " + q.arg(*f.Active))
+	}
+
+	var total int
+	err := r.Conn.QueryRow(ctx, `SELECT COUNT(*) FROM professional_entities e WHERE `+q.whereSQL(), q.args...).Scan(&total)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	limit, offset := q.arg(f.Limit), q.arg(f.Offset)
+	rows, err := r.Conn.Query(ctx, `
+        SELECT `+entityInfoColumns+`
+        WHERE `+q.whereSQL()+`
+        ORDER BY e.updated_at DESC, e.id
+        LIMIT `+limit+` OFFSET `+offset, q.args...)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	entities := []domain.EntityInfo{}
+	for rows.Next() {
+		e, err := scanEntityInfo(rows)
+		if err != nil {
+			return 0, nil, err
+		}
+		entities = append(entities, *e)
+	}
+	return total, entities, rows.Err()
+}
+
+func (r *PostgresAdminRepository) GetEntity(ctx context.Context, id uuid.UUID) (*domain.EntityInfo, error) {
+	e, err := scanEntityInfo(r.Conn.QueryRow(ctx, `SELECT `+entityInfoColumns+` WHERE e.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrProfileNotFound
+	}
+	return e, err
+}
+
+func (r *PostgresAdminRepository) SetEntityActive(ctx context.Context, id uuid.UUID, active bool) error {
+	tag, err := r.Conn.Exec(ctx, `UPDATE professional_entities SET is_active = $2, updated_at = NOW() WHERE id = $1`, id, active)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrProfileNotFound
+	}
+	return nil
+}
+
+// SetEntityStatus exige que el estado encaje con el titular: PROSPECT sin titular,
+// CLAIMED y VERIFIED con él. Se comprueba en el propio UPDATE para no depender de
+// una lectura previa.
+func (r *PostgresAdminRepository) SetEntityStatus(ctx context.Context, id uuid.UUID, status string) error {
+	tag, err := r.Conn.Exec(ctx, `
+        UPDATE professional_entities SET status = $2, updated_at = NOW()
+        WHERE id = $1 AND (user_id IS NULL) = ($2 = 'PROSPECT')`, id, status)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.GetEntity(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrEntityStatusOwner
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"veterimap-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresClaimRepository struct {
+	Conn *pgxpool.Pool
+}
+
+func NewPostgresClaimRepository(db *pgxpool.Pool) *PostgresClaimRepository {
+	return &PostgresClaimRepository{Conn: db}
+}
+
+const claimColumns = `
+        c.id, c.entity_id, e.name, c.user_id, u.email, c.method, c.status, c.destination,
+        c.code_hash, c.attempts, c.expires_at, c.evidence, c.review_note, c.reviewed_by,
+        c.reviewed_at, c.created_at, c.updated_at
+    FROM profile_claims c
+    JOIN professional_entities e ON e.id = c.entity_id
+    JOIN users u ON u.id = c.user_id`
+
+func scanClaim(row pgx.Row) (*domain.ProfileClaim, error) {
+	var c domain.ProfileClaim
+	err := row.Scan(
+		&c.ID, &c.EntityID, &c.EntityName, &c.UserID, &c.UserEmail, &c.Method, &c.Status, &c.Destination,
+		&c.CodeHash, &c.Attempts, &c.ExpiresAt, &c.Evidence, &c.ReviewNote, &c.ReviewedBy,
+		&c.ReviewedAt, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *PostgresClaimRepository) CreateClaim(ctx context.Context, c *domain.ProfileClaim) error {
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Pedir un código nuevo sustituye a la reclamación anterior sobre la misma ficha
+	_, err = tx.Exec(ctx, `
+        UPDATE profile_claims SET status = 'CANCELLED', updated_at = NOW()
+        WHERE entity_id = $1 AND user_id = $2 AND status = 'PENDING'`, c.EntityID, c.UserID)
+	if err != nil {
+		return fmt.Errorf("error cancelando reclamaciones previas: %v", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+        INSERT INTO profile_claims (id, entity_id, user_id, method, status, destination, code_hash, expires_at, evidence)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        RETURNING created_at, updated_at`,
+		c.ID, c.EntityID, c.UserID, c.Method, c.Status, c.Destination, c.CodeHash, c.ExpiresAt, c.Evidence,
+	).Scan(&c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("error creando reclamación: %v", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresClaimRepository) GetClaim(ctx context.Context, id uuid.UUID) (*domain.ProfileClaim, error) {
+	c, err := scanClaim(r.Conn.QueryRow(ctx, `SELECT `+claimColumns+` WHERE c.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrClaimNotFound
+	}
+	return c, err
+}
+
+func (r *PostgresClaimRepository) ListClaimsByUser(ctx context.Context, userID uuid.UUID) ([]domain.ProfileClaim, error) {
+	return r.listClaims(ctx, `SELECT `+claimColumns+` WHERE c.user_id = $1 ORDER BY c.created_at DESC`, userID)
+}
+
+func (r *PostgresClaimRepository) RecentClaims(ctx context.Context, userID uuid.UUID, since time.Time) ([]domain.ProfileClaim, error) {
+	return r.listClaims(ctx, `SELECT `+claimColumns+` WHERE c.user_id = $1 AND c.created_at >= $2 ORDER BY c.created_at DESC`, userID, since)
+}
+
+// ListClaimsByStatus devuelve la cola de revisión, las más antiguas primero
+func (r *PostgresClaimRepository) ListClaimsByStatus(ctx context.Context, status domain.ClaimStatus) ([]domain.ProfileClaim, error) {
+	return r.listClaims(ctx, `SELECT `+claimColumns+` WHERE c.status = $1 ORDER BY c.created_at`, status)
+}
+
+func (r *PostgresClaimRepository) listClaims(ctx context.Context, query string, args ...interface{}) ([]domain.ProfileClaim, error) {
+	rows, err := r.Conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	claims := []domain.ProfileClaim{}
+	for rows.Next() {
+		c, err := scanClaim(rows)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, *c)
+	}
+	return claims, rows.Err()
+}
+
+func (r *PostgresClaimRepository) ConsumeClaimAttempt(ctx context.Context, id uuid.UUID, maxAttempts int) (int, error) {
+	var attempts int
+	err := r.Conn.QueryRow(ctx, `
+        UPDATE profile_claims SET attempts = attempts + 1, updated_at = NOW()
+        WHERE id = $1 AND status = 'PENDING' AND attempts < $2
+        RETURNING attempts`, id, maxAttempts).Scan(&attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, domain.ErrClaimTooManyAttempts
+	}
+	return attempts, err
+}
+
+func (r *PostgresClaimRepository) RejectClaim(ctx context.Context, id uuid.UUID, reviewer uuid.UUID, note string) error {
+	tag, err := r.Conn.Exec(ctx, `
+        UPDATE profile_claims
+        SET status = 'REJECTED', review_note = $2, reviewed_by = $3, reviewed_at = NOW(), updated_at = NOW()
+        WHERE id = $1 AND status = 'PENDING'`, id, note, reviewer)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrClaimNotPending
+	}
+	return nil
+}
+
+func (r *PostgresClaimRepository) CompleteClaim(ctx context.Context, id uuid.UUID, reviewer *uuid.UUID) error {
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// 1. Bloqueamos la reclamación y la ficha para que dos aprobaciones no se crucen
+	var entityID, userID uuid.UUID
+	var status domain.ClaimStatus
+	err = tx.QueryRow(ctx, `SELECT entity_id, user_id, status FROM profile_claims WHERE id = $1 FOR UPDATE`, id).
+		Scan(&entityID, &userID, &status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrClaimNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status != domain.ClaimStatusPending {
+		return domain.ErrClaimNotPending
+	}
+
+	var owner *uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT user_id FROM professional_entities WHERE id = $1 FOR UPDATE`, entityID).Scan(&owner)
+	if err != nil {
+		return err
+	}
+	if owner != nil {
+		return domain.ErrClaimNotAllowed
+	}
+
+	// 2. Si el profesional ya se había creado una ficha propia, su actividad pasa a la
+	// reclamada y la antigua se desactiva (user_id es único por profesional)
+	var previousID *uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT id FROM professional_entities WHERE user_id = $1`, userID).Scan(&previousID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if previousID != nil {
+		moves := []string{
+			`UPDATE appointments SET professional_id = $1 WHERE professional_id = $2`,
+			`UPDATE medical_histories SET professional_id = $1 WHERE professional_id = $2`,
+			`UPDATE reviews SET entity_id = $1 WHERE entity_id = $2`,
+		}
+		for _, q := range moves {
+			if _, err := tx.Exec(ctx, q, entityID, *previousID); err != nil {
+				return fmt.Errorf("error trasladando datos de la ficha anterior: %v", err)
+			}
+		}
+		_, err = tx.Exec(ctx, `
+            UPDATE professional_entities SET user_id = NULL, is_active = false, updated_at = NOW()
+            WHERE id = $1`, *previousID)
+		if err != nil {
+			return fmt.Errorf("error desactivando la ficha anterior: %v", err)
+		}
+		// Las reseñas trasladadas cuentan ya en la valoración de la reclamada, no en la antigua
+		for _, refreshID := range []uuid.UUID{entityID, *previousID} {
+			if _, err := tx.Exec(ctx, `SELECT refresh_entity_rating($1)`, refreshID); err != nil {
+				return fmt.Errorf("error recalculando valoración: %v", err)
+			}
+		}
+	}
+
+	// 3. La ficha cambia de titular; conserva su rating y sus reseñas
+	_, err = tx.Exec(ctx, `
+        UPDATE professional_entities SET user_id = $2, status = $3, updated_at = NOW()
+        WHERE id = $1`, entityID, userID, domain.EntityStatusClaimed)
+	if err != nil {
+		return fmt.Errorf("error asignando la ficha: %v", err)
+	}
+
+	// 4. Cerramos esta reclamación y las demás abiertas sobre la misma ficha
+	_, err = tx.Exec(ctx, `
+        UPDATE profile_claims
+        SET status = 'APPROVED', reviewed_by = $2, reviewed_at = NOW(), updated_at = NOW()
+        WHERE id = $1`, id, reviewer)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+        UPDATE profile_claims
+        SET status = 'REJECTED', review_note = 'La ficha ha sido reclamada por otro profesional', updated_at = NOW()
+        WHERE entity_id = $1 AND id <> $2 AND status = 'PENDING'`, entityID, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,277 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/geo"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresDuplicateRepository struct {
+	Conn *pgxpool.Pool
+}
+
+func NewPostgresDuplicateRepository(db *pgxpool.Pool) *PostgresDuplicateRepository {
+	return &PostgresDuplicateRepository{Conn: db}
+}
+
+// Parejas candidatas: mismo teléfono o alguna dirección a menos de DuplicateMaxDistanceKm.
+// La caja de la autounión (indexada con GiST) se afina después con haversine_km.
+const duplicateCandidatesQuery = `
+    WITH phones AS (
+        SELECT id, normalized_phone(profile_data->'contact'->>'phone') AS phone
+        FROM professional_entities
+    ),
+    pairs AS (
+        SELECT a.id AS a, b.id AS b
+        FROM phones a
+        JOIN phones b ON b.phone = a.phone AND a.id < b.id
+        WHERE length(a.phone) = 9
+        UNION
+        SELECT la.entity_id, lb.entity_id
+        FROM professional_locations la
+        JOIN professional_locations lb
+          ON la.entity_id < lb.entity_id
+         AND point(lb.longitude, lb.latitude) <@ box(
+                 point(la.longitude - $1, la.latitude - $2),
+                 point(la.longitude + $1, la.latitude + $2))
+        WHERE la.latitude IS NOT NULL AND lb.latitude IS NOT NULL
+    ),
+    scored AS (
+        SELECT p.a, p.b,
+               similarity(normalized_name(ea.name), normalized_name(eb.name))::float8 AS name_sim,
+               COALESCE(length(normalized_phone(ea.profile_data->'contact'->>'phone')) = 9
+                    AND normalized_phone(ea.profile_data->'contact'->>'phone')
+                      = normalized_phone(eb.profile_data->'contact'->>'phone'), false) AS same_phone,
+               (SELECT min(haversine_km(la.latitude, la.longitude, lb.latitude, lb.longitude))
+                FROM professional_locations la
+                JOIN professional_locations lb ON lb.entity_id = p.b AND lb.latitude IS NOT NULL
+                WHERE la.entity_id = p.a AND la.latitude IS NOT NULL) AS km
+        FROM pairs p
+        JOIN professional_entities ea ON ea.id = p.a
+        JOIN professional_entities eb ON eb.id = p.b
+        WHERE NOT EXISTS (
+            SELECT 1 FROM duplicate_dismissals d WHERE d.entity_a = p.a AND d.entity_b = p.b)
+    ),
+    ranked AS (
+        SELECT s.*,
+               $3::float8 * s.name_sim
+             + CASE WHEN s.same_phone THEN $4::float8 ELSE 0 END
+             + $5::float8 * COALESCE(greatest(0, 1 - s.km / $6::float8), 0) AS score
+        FROM scored s
+    )
+    SELECT a, b, score, name_sim, same_phone, km, COUNT(*) OVER ()
+    FROM ranked
+    WHERE score >= $7
+    ORDER BY score DESC, a, b
+    LIMIT $8 OFFSET $9`
+
+func (r *PostgresDuplicateRepository) FindDuplicates(ctx context.Context, minScore float64, limit, offset int) (int, []domain.DuplicateCandidate, error) {
+	// Margen de la caja en grados, calculado a 44°N (norte peninsular) para que cubra toda España
+	swLat, swLng, _, _ := geo.BoundsAround(44, 0, domain.DuplicateMaxDistanceKm)
+	dLat, dLng := 44-swLat, -swLng
+
+	rows, err := r.Conn.Query(ctx, duplicateCandidatesQuery,
+		dLng, dLat,
+		domain.DuplicateWeightName, domain.DuplicateWeightPhone, domain.DuplicateWeightDistance,
+		domain.DuplicateMaxDistanceKm, minScore, limit, offset)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error buscando duplicados: %v", err)
+	}
+	defer rows.Close()
+
+	total := 0
+	candidates := []domain.DuplicateCandidate{}
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var c domain.DuplicateCandidate
+		if err := rows.Scan(&c.A.ID, &c.B.ID, &c.Score, &c.NameSimilarity, &c.SamePhone, &c.DistanceKm, &total); err != nil {
+			return 0, nil, err
+		}
+		candidates = append(candidates, c)
+		ids = append(ids, c.A.ID, c.B.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	if len(candidates) == 0 {
+		return total, candidates, nil
+	}
+
+	// Segunda pasada: la ficha resumida de cada lado de la pareja
+	infoRows, err := r.Conn.Query(ctx, `SELECT `+entityInfoColumns+` WHERE e.id = ANY($1)`, ids)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer infoRows.Close()
+
+	infos := map[uuid.UUID]domain.EntityInfo{}
+	for infoRows.Next() {
+		e, err := scanEntityInfo(infoRows)
+		if err != nil {
+			return 0, nil, err
+		}
+		infos[e.ID] = *e
+	}
+	if err := infoRows.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	for i := range candidates {
+		candidates[i].A = infos[candidates[i].A.ID]
+		candidates[i].B = infos[candidates[i].B.ID]
+	}
+	return total, candidates, nil
+}
+
+func (r *PostgresDuplicateRepository) DismissDuplicate(ctx context.Context, a, b, adminID uuid.UUID) error {
+	// La pareja se guarda siempre ordenada
+	if b.String() < a.String() {
+		a, b = b, a
+	}
+	_, err := r.Conn.Exec(ctx, `
+        INSERT INTO duplicate_dismissals (entity_a, entity_b, dismissed_by)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (entity_a, entity_b) DO NOTHING`, a, b, adminID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return domain.ErrProfileNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// mergeRow son los datos de cada ficha que intervienen en la fusión
+type mergeRow struct {
+	entity          domain.ProfessionalEntity
+	baseRating      float64
+	baseReviewCount int
+}
+
+func (r *PostgresDuplicateRepository) MergeEntities(ctx context.Context, m *domain.EntityMerge) error {
+	if m.SurvivorID == m.MergedID {
+		return domain.ErrMergeSameEntity
+	}
+
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// 1. Bloqueamos las dos fichas (en orden de id, para no cruzarnos con otra fusión)
+	rows, err := tx.Query(ctx, `
+        SELECT id, user_id, status, slug, profile_data, is_active, base_rating, base_review_count
+        FROM professional_entities
+        WHERE id IN ($1, $2)
+        ORDER BY id
+        FOR UPDATE`, m.SurvivorID, m.MergedID)
+	if err != nil {
+		return err
+	}
+	found := map[uuid.UUID]*mergeRow{}
+	for rows.Next() {
+		var row mergeRow
+		e := &row.entity
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Status, &e.Slug, &e.ProfileData, &e.IsActive, &row.baseRating, &row.baseReviewCount); err != nil {
+			rows.Close()
+			return err
+		}
+		found[e.ID] = &row
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	survivor, merged := found[m.SurvivorID], found[m.MergedID]
+	if survivor == nil || merged == nil {
+		return domain.ErrProfileNotFound
+	}
+	if survivor.entity.UserID != nil && merged.entity.UserID != nil {
+		return domain.ErrMergeBothOwned
+	}
+	m.MergedSlug = merged.entity.Slug
+
+	// 2. Foto completa de la absorbida antes de tocar nada
+	_, err = tx.Exec(ctx, `
+        INSERT INTO entity_merges (id, survivor_id, merged_id, merged_slug, snapshot, merged_by)
+        SELECT $1, $2, e.id, e.slug, to_jsonb(e), $3
+        FROM professional_entities e WHERE e.id = $4`,
+		m.ID, m.SurvivorID, m.MergedBy, m.MergedID)
+	if err != nil {
+		return fmt.Errorf("error registrando la fusión: %v", err)
+	}
+
+	// 3. Actividad de la absorbida. Sus reclamaciones abiertas se cancelan (la
+	// superviviente puede tener la suya) y el resto se conserva como historial.
+	moves := []string{
+		`UPDATE appointments SET professional_id = $1 WHERE professional_id = $2`,
+		`UPDATE medical_histories SET professional_id = $1 WHERE professional_id = $2`,
+		`UPDATE reviews SET entity_id = $1 WHERE entity_id = $2`,
+		`UPDATE profile_claims SET status = 'CANCELLED', review_note = 'Ficha fusionada con ' || $1::text, updated_at = NOW()
+         WHERE entity_id = $2 AND status = 'PENDING'`,
+		`UPDATE profile_claims SET entity_id = $1 WHERE entity_id = $2`,
+		`UPDATE professional_slug_history SET entity_id = $1 WHERE entity_id = $2`,
+	}
+	for _, q := range moves {
+		if _, err := tx.Exec(ctx, q, m.SurvivorID, m.MergedID); err != nil {
+			return fmt.Errorf("error trasladando datos de la ficha fusionada: %v", err)
+		}
+	}
+
+	// 4. El titular, si solo lo tiene la absorbida, pasa a la superviviente
+	status := survivor.entity.Status
+	owner := survivor.entity.UserID
+	if owner == nil && merged.entity.UserID != nil {
+		owner, status = merged.entity.UserID, merged.entity.Status
+	}
+
+	// 5. Borramos la absorbida; su slug queda como redirección a la superviviente
+	if _, err := tx.Exec(ctx, `DELETE FROM professional_entities WHERE id = $1`, m.MergedID); err != nil {
+		return fmt.Errorf("error borrando la ficha fusionada: %v", err)
+	}
+	if m.MergedSlug != nil && *m.MergedSlug != "" {
+		_, err = tx.Exec(ctx, `
+            INSERT INTO professional_slug_history (slug, entity_id) VALUES ($1, $2)
+            ON CONFLICT (slug) DO UPDATE SET entity_id = EXCLUDED.entity_id, created_at = now()`,
+			*m.MergedSlug, m.SurvivorID)
+		if err != nil {
+			return fmt.Errorf("error redirigiendo el slug fusionado: %v", err)
+		}
+	}
+
+	// 6. Datos consolidados. Las valoraciones importadas de dos fichas de la misma
+	// clínica suelen ser las mismas reseñas: nos quedamos con la base más amplia.
+	data := domain.MergeProfileData(survivor.entity.ProfileData, merged.entity.ProfileData)
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error serializing profile data: %v", err)
+	}
+	base := survivor
+	if merged.baseReviewCount > survivor.baseReviewCount {
+		base = merged
+	}
+	_, err = tx.Exec(ctx, `
+        UPDATE professional_entities
+        SET user_id = $2, status = $3, profile_data = $4, is_active = $5,
+            base_rating = $6, base_review_count = $7, updated_at = NOW()
+        WHERE id = $1`,
+		m.SurvivorID, owner, status, dataJSON, survivor.entity.IsActive || merged.entity.IsActive,
+		base.baseRating, base.baseReviewCount)
+	if err != nil {
+		return fmt.Errorf("error guardando la ficha consolidada: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `SELECT refresh_entity_rating($1)`, m.SurvivorID); err != nil {
+		return fmt.Errorf("error recalculando valoración: %v", err)
+	}
+
+	return tx.Commit(ctx)
+}
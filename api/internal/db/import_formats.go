@@ -0,0 +1,522 @@
+package db
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"veterimap-api/internal/pkg/slug"
+)
+
+// Formatos de volcado que entiende el importador
+const (
+	ImportFormatJSON    = "json"    // clinicas.json (ClinicaJSON)
+	ImportFormatCSV     = "csv"     // Directorios de colegios y socios
+	ImportFormatGeoJSON = "geojson" // FeatureCollection de puntos
+)
+
+var (
+	ErrImportFormat  = errors.New("formato de importación no soportado")
+	ErrImportMapping = errors.New("mapeo de columnas no válido")
+)
+
+// ImportMapping indica en qué columna (CSV) o propiedad (GeoJSON) viene cada dato.
+// Se carga de un fichero JSON con --mapping; las columnas vacías no se importan.
+//
+//	{"name": "NOMBRE", "city": "MUNICIPIO", "phone": "TELEFONO", "type": "TIPO",
+//	 "types": {"urgencias": "HOSPITAL"}, "delimiter": ";"}
+type ImportMapping struct {
+	Name       string `json:"name"`
+	Address    string `json:"address"`
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code"`
+	Lat        string `json:"lat"` // En GeoJSON manda la geometría; la propiedad es el respaldo
+	Lng        string `json:"lng"`
+	Phone      string `json:"phone"`
+	Email      string `json:"email"`
+	Type       string `json:"type"`
+	Hours      string `json:"hours"`
+	Rating     string `json:"rating"`
+	Reviews    string `json:"reviews"`
+
+	// Types añade palabras clave (del valor de la columna type o del nombre) → tipo de ficha
+	Types       map[string]string `json:"types"`
+	DefaultType string            `json:"default_type"`
+	Delimiter   string            `json:"delimiter"` // Solo CSV; vacío = se detecta con la cabecera
+}
+
+// DefaultImportMapping usa los mismos nombres de campo que clinicas.json
+func DefaultImportMapping() ImportMapping {
+	return ImportMapping{
+		Name:        "nombre",
+		Address:     "direccion",
+		City:        "ciudad",
+		PostalCode:  "codigo_postal",
+		Lat:         "lat",
+		Lng:         "lng",
+		Phone:       "telefono",
+		Email:       "email",
+		Type:        "tipo",
+		Hours:       "horario",
+		Rating:      "rating",
+		Reviews:     "reviews",
+		DefaultType: "CLINIC",
+	}
+}
+
+// defaultTypeKeywords clasifica por el texto del tipo o, si no hay, por el nombre.
+// Las claves van normalizadas con slug.Make.
+var defaultTypeKeywords = map[string]string{
+	"clinic":       "CLINIC",
+	"clinica":      "CLINIC",
+	"consultorio":  "CLINIC",
+	"hospital":     "HOSPITAL",
+	"urgencias-24": "HOSPITAL",
+	"home-vet":     "HOME_VET",
+	"domicilio":    "HOME_VET",
+	"ambulante":    "HOME_VET",
+	"movil":        "HOME_VET",
+}
+
+var entityTypes = map[string]bool{"CLINIC": true, "HOSPITAL": true, "HOME_VET": true}
+
+// LoadImportMapping lee un mapeo propio; sin fichero se usa DefaultImportMapping
+func LoadImportMapping(path string) (ImportMapping, error) {
+	m := DefaultImportMapping()
+	if path == "" {
+		return m, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return m, fmt.Errorf("no se pudo abrir %s: %v", path, err)
+	}
+	var custom ImportMapping
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return m, fmt.Errorf("%w: %v", ErrImportMapping, err)
+	}
+
+	// Un mapeo propio sustituye las columnas; solo el tipo por defecto se hereda
+	if custom.DefaultType == "" {
+		custom.DefaultType = m.DefaultType
+	}
+	return custom, custom.validate()
+}
+
+func (m ImportMapping) validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("%w: falta la columna name", ErrImportMapping)
+	}
+	if !entityTypes[m.DefaultType] {
+		return fmt.Errorf("%w: default_type %q no es CLINIC, HOSPITAL ni HOME_VET", ErrImportMapping, m.DefaultType)
+	}
+	for keyword, t := range m.Types {
+		if !entityTypes[t] {
+			return fmt.Errorf("%w: el tipo %q de %q no es CLINIC, HOSPITAL ni HOME_VET", ErrImportMapping, t, keyword)
+		}
+	}
+	if len([]rune(m.Delimiter)) > 1 {
+		return fmt.Errorf("%w: el delimitador debe ser un único carácter", ErrImportMapping)
+	}
+	return nil
+}
+
+// DetectImportFormat deduce el formato por la extensión del fichero
+func DetectImportFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ImportFormatJSON, nil
+	case ".csv", ".tsv":
+		return ImportFormatCSV, nil
+	case ".geojson":
+		return ImportFormatGeoJSON, nil
+	}
+	return "", fmt.Errorf("%w: %s (usa --format json|csv|geojson)", ErrImportFormat, path)
+}
+
+// ReadCSVRecords lee un CSV con cabecera. Las filas que no pasan la validación
+// quedan en report.Failed con su número de línea y no se devuelven.
+func ReadCSVRecords(r io.Reader, m ImportMapping, report *ImportReport) ([]ImportRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimPrefix(string(data), "\ufeff") // BOM de Excel
+
+	delimiter := m.Delimiter
+	if delimiter == "" {
+		delimiter = detectDelimiter(text)
+	}
+
+	cr := csv.NewReader(strings.NewReader(text))
+	cr.Comma = []rune(delimiter)[0]
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo la cabecera del CSV: %v", err)
+	}
+	index := map[string]int{}
+	for i, col := range header {
+		index[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+	if _, ok := index[strings.ToLower(m.Name)]; !ok {
+		return nil, fmt.Errorf("%w: la columna de nombre %q no está en la cabecera", ErrImportMapping, m.Name)
+	}
+	for _, col := range m.columns() {
+		if _, ok := index[strings.ToLower(col)]; !ok {
+			log.Printf("⚠️ La columna %q del mapeo no está en la cabecera; se importará vacía", col)
+		}
+	}
+
+	var records []ImportRecord
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := cr.FieldPos(0)
+		if err != nil {
+			report.Total++
+			report.Failed = append(report.Failed, ImportIssue{Row: line, Reason: err.Error()})
+			continue
+		}
+		if isBlankRow(row) {
+			continue
+		}
+
+		get := func(col string) string {
+			if col == "" {
+				return ""
+			}
+			if i, ok := index[strings.ToLower(col)]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		rec, err := m.toRecord(line, get, nil, report)
+		if err != nil {
+			report.Total++
+			report.Failed = append(report.Failed, ImportIssue{Row: line, Name: get(m.Name), Reason: err.Error()})
+			continue
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// geoJSONFeature admite cualquier geometría para poder rechazar fila a fila las que no son puntos
+type geoJSONFeature struct {
+	Geometry *struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// ReadGeoJSONRecords lee una FeatureCollection de puntos ([lng, lat]); los datos
+// de la ficha salen de las propiedades según el mapeo
+func ReadGeoJSONRecords(r io.Reader, m ImportMapping, report *ImportReport) ([]ImportRecord, error) {
+	var fc struct {
+		Type     string           `json:"type"`
+		Features []geoJSONFeature `json:"features"`
+	}
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, fmt.Errorf("error al parsear GeoJSON: %v", err)
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("%w: se espera una FeatureCollection y llega %q", ErrImportFormat, fc.Type)
+	}
+
+	var records []ImportRecord
+	for i, f := range fc.Features {
+		row := i + 1
+		get := func(col string) string {
+			if col == "" {
+				return ""
+			}
+			return propertyString(f.Properties, col)
+		}
+
+		coords, err := f.point()
+		if err == nil {
+			var rec ImportRecord
+			rec, err = m.toRecord(row, get, coords, report)
+			if err == nil {
+				records = append(records, rec)
+				continue
+			}
+		}
+		report.Total++
+		report.Failed = append(report.Failed, ImportIssue{Row: row, Name: get(m.Name), Reason: err.Error()})
+	}
+	return records, nil
+}
+
+// point devuelve [lat, lng] de un Point, o nil si el feature no trae geometría
+func (f geoJSONFeature) point() (*[2]float64, error) {
+	if f.Geometry == nil {
+		return nil, nil
+	}
+	if f.Geometry.Type != "Point" {
+		return nil, fmt.Errorf("geometría %s no soportada (solo Point)", f.Geometry.Type)
+	}
+	var c []float64
+	if err := json.Unmarshal(f.Geometry.Coordinates, &c); err != nil || len(c) < 2 {
+		return nil, errors.New("coordenadas del punto no válidas")
+	}
+	return &[2]float64{c[1], c[0]}, nil
+}
+
+// toRecord valida y normaliza una fila. coords, si viene, tiene prioridad sobre
+// las columnas lat/lng.
+func (m ImportMapping) toRecord(row int, get func(string) string, coords *[2]float64, report *ImportReport) (ImportRecord, error) {
+	name := get(m.Name)
+	if name == "" {
+		return ImportRecord{}, errors.New("falta el nombre")
+	}
+
+	var lat, lng float64
+	if coords != nil {
+		lat, lng = coords[0], coords[1]
+	} else {
+		var err error
+		if lat, err = parseCoord(get(m.Lat)); err != nil {
+			return ImportRecord{}, fmt.Errorf("latitud: %v", err)
+		}
+		if lng, err = parseCoord(get(m.Lng)); err != nil {
+			return ImportRecord{}, fmt.Errorf("longitud: %v", err)
+		}
+	}
+	lat, lng, err := normalizeCoords(lat, lng)
+	if err != nil {
+		return ImportRecord{}, err
+	}
+
+	phone, err := normalizePhone(get(m.Phone))
+	if err != nil {
+		return ImportRecord{}, err
+	}
+
+	var rating float64
+	if v := get(m.Rating); v != "" {
+		if rating = toFloat(v); rating < 0 || rating > 5 {
+			return ImportRecord{}, fmt.Errorf("valoración %q fuera de rango (0-5)", v)
+		}
+	}
+
+	city := get(m.City)
+	hours := get(m.Hours)
+	return ImportRecord{
+		Row:        row,
+		Name:       name,
+		City:       city,
+		EntityType: m.classify(get(m.Type), name),
+		Rating:     rating,
+		Reviews:    int(toFloat(get(m.Reviews))),
+		ProfileData: map[string]interface{}{
+			"addresses": []map[string]interface{}{
+				{
+					"full_address": get(m.Address),
+					"city":         city,
+					"postal_code":  get(m.PostalCode),
+					"latitude":     lat, // 0 = sin coordenadas; las completa cmd/tools/geocodificar_base.go
+					"longitude":    lng,
+					"is_main":      true,
+				},
+			},
+			"contact": map[string]string{
+				"phone": phone,
+				"email": strings.ToLower(get(m.Email)),
+			},
+			"working_hours": report.parseHours(name, hours),
+			"schedule_text": hours,
+			"specialties":   []string{},
+		},
+	}, nil
+}
+
+// classify decide el tipo de ficha: primero el valor de la columna type (exacto o
+// por palabra clave), después palabras clave en el nombre y si no, el tipo por defecto
+func (m ImportMapping) classify(typeValue, name string) string {
+	if t := strings.ToUpper(strings.TrimSpace(typeValue)); entityTypes[t] {
+		return t
+	}
+
+	keywords := make(map[string]string, len(defaultTypeKeywords)+len(m.Types))
+	for k, t := range defaultTypeKeywords {
+		keywords[k] = t
+	}
+	for k, t := range m.Types {
+		keywords[slug.Make(k)] = t
+	}
+	// Lo específico gana a lo genérico ("Clínica móvil" es HOME_VET) y, dentro de
+	// cada tipo, las claves más largas primero
+	priority := map[string]int{"HOSPITAL": 0, "HOME_VET": 1, "CLINIC": 2}
+	ordered := make([]string, 0, len(keywords))
+	for k := range keywords {
+		ordered = append(ordered, k)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if priority[keywords[a]] != priority[keywords[b]] {
+			return priority[keywords[a]] < priority[keywords[b]]
+		}
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+
+	for _, text := range []string{typeValue, name} {
+		words := "-" + slug.Make(text) + "-"
+		for _, k := range ordered {
+			if strings.Contains(words, "-"+k+"-") {
+				return keywords[k]
+			}
+		}
+	}
+	return m.DefaultType
+}
+
+// columns son las columnas que el mapeo espera encontrar en la cabecera
+func (m ImportMapping) columns() []string {
+	var cols []string
+	for _, c := range []string{m.Name, m.Address, m.City, m.PostalCode, m.Lat, m.Lng, m.Phone, m.Email, m.Type, m.Hours, m.Rating, m.Reviews} {
+		if c != "" {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+// parseCoord acepta coma o punto decimal; vacío es 0 (sin coordenadas)
+func parseCoord(v string) (float64, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(strings.Replace(v, ",", ".", 1), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q no es un número", v)
+	}
+	return f, nil
+}
+
+// spainBounds es la caja de España (con Canarias) para detectar lat/lng intercambiadas
+var spainBounds = struct{ minLat, maxLat, minLng, maxLng float64 }{27.5, 44, -18.5, 4.5}
+
+func inSpain(lat, lng float64) bool {
+	return lat >= spainBounds.minLat && lat <= spainBounds.maxLat && lng >= spainBounds.minLng && lng <= spainBounds.maxLng
+}
+
+// normalizeCoords valida los rangos y corrige el error habitual de lat/lng
+// intercambiadas. (0, 0) se conserva como "sin coordenadas".
+func normalizeCoords(lat, lng float64) (float64, float64, error) {
+	if lat == 0 && lng == 0 {
+		return 0, 0, nil
+	}
+	if lat == 0 || lng == 0 {
+		return 0, 0, fmt.Errorf("coordenadas incompletas (%v, %v)", lat, lng)
+	}
+	if !inSpain(lat, lng) && inSpain(lng, lat) {
+		lat, lng = lng, lat
+	}
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return 0, 0, fmt.Errorf("coordenadas fuera de rango (%v, %v)", lat, lng)
+	}
+	return lat, lng, nil
+}
+
+// normalizePhone deja los teléfonos españoles como "617 51 26 29" (el formato de
+// clinicas.json) y los extranjeros como +<dígitos>. Si hay varios, se queda con el primero.
+func normalizePhone(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return "", nil
+	}
+	if i := strings.IndexAny(v, "/|;"); i > 0 {
+		v = v[:i]
+	}
+
+	international := strings.HasPrefix(v, "+") || strings.HasPrefix(v, "00")
+	var digits strings.Builder
+	for _, r := range v {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	d := digits.String()
+	if strings.HasPrefix(v, "00") {
+		d = strings.TrimPrefix(d, "00")
+	}
+	if international && strings.HasPrefix(d, "34") && len(d) == 11 {
+		d, international = d[2:], false
+	}
+
+	switch {
+	case international && len(d) >= 8 && len(d) <= 15:
+		return "+" + d, nil
+	case !international && len(d) == 9 && strings.ContainsRune("6789", rune(d[0])):
+		return fmt.Sprintf("%s %s %s %s", d[0:3], d[3:5], d[5:7], d[7:9]), nil
+	}
+	return "", fmt.Errorf("teléfono %q no válido", v)
+}
+
+// detectDelimiter elige entre ; , y tabulador según la cabecera
+func detectDelimiter(data string) string {
+	header := data
+	if i := strings.IndexByte(data, '\n'); i >= 0 {
+		header = data[:i]
+	}
+	best, count := ",", strings.Count(header, ",")
+	for _, d := range []string{";", "\t"} {
+		if n := strings.Count(header, d); n > count {
+			best, count = d, n
+		}
+	}
+	return best
+}
+
+func isBlankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// propertyString busca una propiedad sin distinguir mayúsculas y la pasa a texto
+func propertyString(props map[string]interface{}, key string) string {
+	v, ok := props[key]
+	if !ok {
+		for k, val := range props {
+			if strings.EqualFold(k, key) {
+				v, ok = val, true
+				break
+			}
+		}
+	}
+	if !ok || v == nil {
+		return ""
+	}
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return strings.TrimSpace(fmt.Sprint(t))
+	}
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+// importedRow resume lo que interesa comprobar de un ImportRecord
+type importedRow struct {
+	Row        int
+	Name       string
+	City       string
+	EntityType string
+	Lat, Lng   float64
+	Phone      string
+}
+
+func summarize(records []ImportRecord) []importedRow {
+	var rows []importedRow
+	for _, r := range records {
+		addr := r.ProfileData["addresses"].([]map[string]interface{})[0]
+		contact := r.ProfileData["contact"].(map[string]string)
+		rows = append(rows, importedRow{
+			Row:        r.Row,
+			Name:       r.Name,
+			City:       r.City,
+			EntityType: r.EntityType,
+			Lat:        addr["latitude"].(float64),
+			Lng:        addr["longitude"].(float64),
+			Phone:      contact["phone"],
+		})
+	}
+	return rows
+}
+
+func failedRows(report ImportReport) []int {
+	var rows []int
+	for _, f := range report.Failed {
+		rows = append(rows, f.Row)
+	}
+	return rows
+}
+
+func TestReadCSVRecords(t *testing.T) {
+	colegio := ImportMapping{
+		Name: "NOMBRE", City: "MUNICIPIO", Phone: "TELEFONO", Type: "TIPO",
+		Lat: "LAT", Lng: "LNG", Hours: "HORARIO",
+		Types:       map[string]string{"Urgencias": "HOSPITAL"},
+		DefaultType: "CLINIC",
+	}
+
+	tests := []struct {
+		name       string
+		csv        string
+		mapping    ImportMapping
+		want       []importedRow
+		wantFailed []int
+		wantErr    bool
+	}{
+		{
+			name: "punto y coma con BOM y coma decimal",
+			csv: "\ufeffNOMBRE;MUNICIPIO;TELEFONO;TIPO;LAT;LNG;HORARIO\n" +
+				"Clínica Sol;Sevilla;954 12 34 56;;37,3891;-5,9845;L-V 9-14\n" +
+				"Hospital Norte;Bilbao;+34 944 000 111;urgencias;43.263;-2.935;24h\n",
+			mapping: colegio,
+			want: []importedRow{
+				{Row: 2, Name: "Clínica Sol", City: "Sevilla", EntityType: "CLINIC", Lat: 37.3891, Lng: -5.9845, Phone: "954 12 34 56"},
+				{Row: 3, Name: "Hospital Norte", City: "Bilbao", EntityType: "HOSPITAL", Lat: 43.263, Lng: -2.935, Phone: "944 00 01 11"},
+			},
+		},
+		{
+			name: "lat/lng intercambiadas y tipo por el nombre",
+			csv: "NOMBRE,MUNICIPIO,TELEFONO,TIPO,LAT,LNG\n" +
+				"Veterinario a domicilio Ana,Madrid,600112233,,-3.7038,40.4168\n",
+			mapping: colegio,
+			want: []importedRow{
+				{Row: 2, Name: "Veterinario a domicilio Ana", City: "Madrid", EntityType: "HOME_VET", Lat: 40.4168, Lng: -3.7038, Phone: "600 11 22 33"},
+			},
+		},
+		{
+			name: "filas inválidas al informe y filas en blanco ignoradas",
+			csv: "NOMBRE;MUNICIPIO;TELEFONO;TIPO;LAT;LNG\n" +
+				";Huesca;974000000;;;\n" +
+				";;;;;\n" +
+				"Clínica Teléfono;Huesca;12345;;;\n" +
+				"Clínica Sin Lng;Huesca;;;42.1;\n" +
+				"Clínica Buena;Huesca;;;;\n",
+			mapping:    colegio,
+			want:       []importedRow{{Row: 6, Name: "Clínica Buena", City: "Huesca", EntityType: "CLINIC"}},
+			wantFailed: []int{2, 4, 5},
+		},
+		{
+			name:    "sin la columna del nombre",
+			csv:     "NAME;CITY\nClínica;Teruel\n",
+			mapping: colegio,
+			wantErr: true,
+		},
+		{
+			name: "mapeo por defecto (campos de clinicas.json)",
+			csv: "nombre,ciudad,telefono,tipo,lat,lng\n" +
+				"Clínica Mar,Cádiz,956 11 22 33,HOSPITAL,36.5271,-6.2886\n",
+			mapping: DefaultImportMapping(),
+			want: []importedRow{
+				{Row: 2, Name: "Clínica Mar", City: "Cádiz", EntityType: "HOSPITAL", Lat: 36.5271, Lng: -6.2886, Phone: "956 11 22 33"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var report ImportReport
+			records, err := ReadCSVRecords(strings.NewReader(tt.csv), tt.mapping, &report)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("se esperaba un error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadCSVRecords: %v", err)
+			}
+			assertImported(t, summarize(records), tt.want)
+			assertFailed(t, failedRows(report), tt.wantFailed)
+		})
+	}
+}
+
+func TestReadGeoJSONRecords(t *testing.T) {
+	mapping := ImportMapping{Name: "name", City: "city", Phone: "phone", Lat: "lat", Lng: "lng", DefaultType: "CLINIC"}
+
+	tests := []struct {
+		name       string
+		geojson    string
+		want       []importedRow
+		wantFailed []int
+		wantErr    bool
+	}{
+		{
+			name: "la geometría manda sobre las propiedades",
+			geojson: `{"type": "FeatureCollection", "features": [
+				{"type": "Feature", "geometry": {"type": "Point", "coordinates": [-0.8891, 41.6488]},
+				 "properties": {"NAME": "Clínica Ebro", "city": "Zaragoza", "phone": 976123456, "lat": 1, "lng": 1}}]}`,
+			want: []importedRow{
+				{Row: 1, Name: "Clínica Ebro", City: "Zaragoza", EntityType: "CLINIC", Lat: 41.6488, Lng: -0.8891, Phone: "976 12 34 56"},
+			},
+		},
+		{
+			name: "sin geometría se usan las propiedades",
+			geojson: `{"type": "FeatureCollection", "features": [
+				{"type": "Feature", "geometry": null, "properties": {"name": "Hospital Veterinario Sur", "lat": "36,72", "lng": "-4,42"}}]}`,
+			want: []importedRow{
+				{Row: 1, Name: "Hospital Veterinario Sur", EntityType: "HOSPITAL", Lat: 36.72, Lng: -4.42},
+			},
+		},
+		{
+			name: "geometrías que no son puntos y features sin nombre",
+			geojson: `{"type": "FeatureCollection", "features": [
+				{"type": "Feature", "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 1], [1, 0], [0, 0]]]}, "properties": {"name": "Parcela"}},
+				{"type": "Feature", "geometry": {"type": "Point", "coordinates": [-3.7, 40.4]}, "properties": {}},
+				{"type": "Feature", "geometry": {"type": "Point", "coordinates": [-3.7, 40.4]}, "properties": {"name": "Clínica Centro"}}]}`,
+			want:       []importedRow{{Row: 3, Name: "Clínica Centro", EntityType: "CLINIC", Lat: 40.4, Lng: -3.7}},
+			wantFailed: []int{1, 2},
+		},
+		{
+			name:    "no es una FeatureCollection",
+			geojson: `{"type": "Feature", "geometry": null, "properties": {}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var report ImportReport
+			records, err := ReadGeoJSONRecords(strings.NewReader(tt.geojson), mapping, &report)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("se esperaba un error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadGeoJSONRecords: %v", err)
+			}
+			assertImported(t, summarize(records), tt.want)
+			assertFailed(t, failedRows(report), tt.wantFailed)
+		})
+	}
+}
+
+func assertImported(t *testing.T, got, want []importedRow) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%d registros, se esperaban %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("registro %d = %+v, se esperaba %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func assertFailed(t *testing.T, got, want []int) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("filas fallidas = %v, se esperaban %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("filas fallidas = %v, se esperaban %v", got, want)
+			return
+		}
+	}
+}
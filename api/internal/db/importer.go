@@ -0,0 +1,402 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/slug"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type ClinicaJSON struct {
+	Nombre    string      `json:"nombre"`
+	Direccion string      `json:"direccion"`
+	Ciudad    string      `json:"ciudad"`
+	Lat       interface{} `json:"lat"`
+	Lng       interface{} `json:"lng"`
+	Telefono  string      `json:"telefono"`
+	Rating    interface{} `json:"rating"`
+	Reviews   interface{} `json:"reviews"`
+	Horario   string      `json:"horario"`
+	TipoFicha string      `json:"tipoFicha"`
+}
+
+// ImportOptions controla una importación (ver cmd/tools/importar)
+type ImportOptions struct {
+	Source  string // Procedencia que queda en import_source, p. ej. "google-places-2025-10"
+	DryRun  bool   // Clasifica y cuenta, pero no escribe nada
+	OnlyNew bool   // Solo inserta; las fichas que ya existen no se tocan
+}
+
+// ImportRecord es una ficha lista para importar, venga del formato que venga
+type ImportRecord struct {
+	Row         int // Posición en el origen (línea del CSV, nº de feature...) para el informe
+	Name        string
+	City        string
+	EntityType  string
+	Rating      float64
+	Reviews     int
+	ProfileData map[string]interface{}
+}
+
+// ImportReport resume el resultado de una importación
+type ImportReport struct {
+	Source        string
+	DryRun        bool
+	Total         int
+	Inserted      int
+	Updated       int
+	Skipped       []ImportIssue // Protegidas, retiradas o ya existentes con --only-new
+	Failed        []ImportIssue
+	WithoutHours  int             // Registros sin horario en origen
+	UnparsedHours []UnparsedHours // Horarios que el parser no ha sabido interpretar
+}
+
+// ImportIssue identifica un registro que no se ha importado y por qué
+type ImportIssue struct {
+	Row    int
+	Name   string
+	Slug   string
+	Reason string
+}
+
+// UnparsedHours identifica un horario que habrá que revisar a mano
+type UnparsedHours struct {
+	Name string
+	Text string
+	Err  error
+}
+
+// ImportClinicasFile importa un volcado JSON con el formato de clinicas.json
+func ImportClinicasFile(ctx context.Context, path string, opts ImportOptions) (ImportReport, error) {
+	report := ImportReport{Source: opts.Source, DryRun: opts.DryRun}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, fmt.Errorf("no se pudo abrir %s: %v", path, err)
+	}
+
+	var clinicas []ClinicaJSON
+	if err := json.Unmarshal(data, &clinicas); err != nil {
+		return report, fmt.Errorf("error al parsear JSON: %v", err)
+	}
+
+	records := make([]ImportRecord, 0, len(clinicas))
+	for i, c := range clinicas {
+		rec := c.toRecord(&report)
+		rec.Row = i + 1
+		records = append(records, rec)
+	}
+	return report, ImportRecords(ctx, records, opts, &report)
+}
+
+// ImportFile importa un volcado en cualquiera de los formatos soportados. El
+// mapeo solo se usa con CSV y GeoJSON; clinicas.json tiene su propio formato.
+func ImportFile(ctx context.Context, path, format string, mapping ImportMapping, opts ImportOptions) (ImportReport, error) {
+	if format == "" {
+		var err error
+		if format, err = DetectImportFormat(path); err != nil {
+			return ImportReport{Source: opts.Source, DryRun: opts.DryRun}, err
+		}
+	}
+	if format == ImportFormatJSON {
+		return ImportClinicasFile(ctx, path, opts)
+	}
+
+	report := ImportReport{Source: opts.Source, DryRun: opts.DryRun}
+	f, err := os.Open(path)
+	if err != nil {
+		return report, fmt.Errorf("no se pudo abrir %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var records []ImportRecord
+	switch format {
+	case ImportFormatCSV:
+		records, err = ReadCSVRecords(f, mapping, &report)
+	case ImportFormatGeoJSON:
+		records, err = ReadGeoJSONRecords(f, mapping, &report)
+	default:
+		err = fmt.Errorf("%w: %s", ErrImportFormat, format)
+	}
+	if err != nil {
+		return report, err
+	}
+	return report, ImportRecords(ctx, records, opts, &report)
+}
+
+// toRecord traduce un registro de clinicas.json al formato común
+func (c ClinicaJSON) toRecord(report *ImportReport) ImportRecord {
+	var entityType string
+	switch c.TipoFicha {
+	case "fichas_clinicas":
+		entityType = "CLINIC"
+	case "fichas_hospitales":
+		entityType = "HOSPITAL"
+	case "fichas_veterinarios":
+		entityType = "HOME_VET"
+	default:
+		entityType = "CLINIC"
+	}
+
+	return ImportRecord{
+		Name:       c.Nombre,
+		City:       c.Ciudad,
+		EntityType: entityType,
+		Rating:     toFloat(c.Rating),
+		Reviews:    int(toFloat(c.Reviews)),
+		ProfileData: map[string]interface{}{
+			"addresses": []map[string]interface{}{
+				{
+					"full_address": c.Direccion,
+					"city":         c.Ciudad,
+					"latitude":     sanitizeCoord(c.Lat),
+					"longitude":    sanitizeCoord(c.Lng),
+					"is_main":      true,
+				},
+			},
+			"contact": map[string]string{
+				"phone": c.Telefono,
+			},
+			"working_hours": report.parseHours(c.Nombre, c.Horario),
+			"schedule_text": strings.TrimSpace(c.Horario),
+			"specialties":   []string{},
+		},
+	}
+}
+
+// parseHours convierte el horario en texto libre a WorkingDay por día. Si no se
+// entiende, se deja vacío (horario desconocido) y el texto original se conserva
+// en schedule_text para mostrarlo en el perfil.
+func (r *ImportReport) parseHours(name, text string) map[string]domain.WorkingDay {
+	workingHours, err := domain.ParseSchedule(text)
+	switch {
+	case err != nil:
+		r.UnparsedHours = append(r.UnparsedHours, UnparsedHours{Name: name, Text: text, Err: err})
+		workingHours = nil
+	case workingHours == nil:
+		r.WithoutHours++
+	}
+	if workingHours == nil {
+		workingHours = map[string]domain.WorkingDay{}
+	}
+	return workingHours
+}
+
+// importAction es lo que se hará con un registro según lo que ya hay en la base de datos
+type importAction int
+
+const (
+	importInsert importAction = iota
+	importUpdate
+	importSkip
+)
+
+// classifyImport decide qué hacer con un slug. Nunca se sobrescriben fichas con
+// titular o fuera de PROSPECT, ni se recrean slugs que ya redirigen a otra ficha
+// (fusionada o renombrada por su titular).
+func classifyImport(ctx context.Context, entitySlug string, opts ImportOptions) (importAction, string, error) {
+	var retired bool
+	var status *string
+	var owned bool
+	err := Conn.QueryRow(ctx, `
+        SELECT EXISTS (SELECT 1 FROM professional_slug_history WHERE slug = $1),
+               e.status, e.user_id IS NOT NULL
+        FROM (SELECT 1) dummy
+        LEFT JOIN professional_entities e ON e.slug = $1`, entitySlug).Scan(&retired, &status, &owned)
+	if err != nil {
+		return importSkip, "", err
+	}
+
+	switch {
+	case retired:
+		return importSkip, "el slug redirige a otra ficha", nil
+	case status == nil:
+		return importInsert, "", nil
+	case owned || *status != domain.EntityStatusProspect:
+		return importSkip, fmt.Sprintf("ficha protegida (%s)", *status), nil
+	case opts.OnlyNew:
+		return importSkip, "ya existe (--only-new)", nil
+	default:
+		return importUpdate, "", nil
+	}
+}
+
+// ImportRecords clasifica e importa los registros uno a uno como PROSPECT. El
+// slug es determinista (sin sufijos) para que reimportar actualice la misma ficha.
+func ImportRecords(ctx context.Context, records []ImportRecord, opts ImportOptions, report *ImportReport) error {
+	report.Total += len(records)
+	log.Printf("🚀 Importando %d registros (source=%q, dry-run=%v, only-new=%v)...", len(records), opts.Source, opts.DryRun, opts.OnlyNew)
+
+	for i, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if i > 0 && i%200 == 0 {
+			log.Printf("... %d registros procesados", i)
+		}
+
+		entitySlug := slug.Make(rec.Name, rec.City)
+		if entitySlug == "" {
+			entitySlug = slug.Fallback
+		}
+		issue := ImportIssue{Row: rec.Row, Name: rec.Name, Slug: entitySlug}
+
+		action, reason, err := classifyImport(ctx, entitySlug, opts)
+		if err != nil {
+			issue.Reason = err.Error()
+			report.Failed = append(report.Failed, issue)
+			continue
+		}
+		if action == importSkip {
+			issue.Reason = reason
+			report.Skipped = append(report.Skipped, issue)
+			continue
+		}
+
+		if !opts.DryRun {
+			written, err := writeImportRecord(ctx, action, entitySlug, rec, opts.Source)
+			if err != nil {
+				issue.Reason = err.Error()
+				report.Failed = append(report.Failed, issue)
+				continue
+			}
+			if !written {
+				// Alguien la ha reclamado o creado entre la clasificación y la escritura
+				issue.Reason = "ha cambiado durante la importación"
+				report.Skipped = append(report.Skipped, issue)
+				continue
+			}
+		}
+
+		if action == importInsert {
+			report.Inserted++
+		} else {
+			report.Updated++
+		}
+	}
+	return nil
+}
+
+// writeImportRecord inserta o actualiza la ficha. La protección se repite en el
+// SQL por si la ficha cambia entre la clasificación y la escritura; en ese caso
+// devuelve false.
+func writeImportRecord(ctx context.Context, action importAction, entitySlug string, rec ImportRecord, source string) (bool, error) {
+	profileDataJSON, err := json.Marshal(rec.ProfileData)
+	if err != nil {
+		return false, fmt.Errorf("error serializing profile data: %v", err)
+	}
+
+	var id string
+	if action == importInsert {
+		err = Conn.QueryRow(ctx, `
+            INSERT INTO professional_entities (
+                entity_type, status, name, slug, rating, review_count,
+                base_rating, base_review_count, profile_data, is_active, import_source, imported_at
+            ) VALUES ($1, 'PROSPECT', $2, $3, $4, $5, $4, $5, $6, true, NULLIF($7, ''), NOW())
+            ON CONFLICT (slug) DO NOTHING
+            RETURNING id`,
+			rec.EntityType, rec.Name, entitySlug, rec.Rating, rec.Reviews, profileDataJSON, source,
+		).Scan(&id)
+	} else {
+		err = Conn.QueryRow(ctx, `
+            UPDATE professional_entities SET
+                entity_type = $2,
+                base_rating = $3,
+                base_review_count = $4,
+                profile_data = $5,
+                import_source = COALESCE(NULLIF($6, ''), import_source),
+                imported_at = NOW(),
+                updated_at = NOW()
+            WHERE slug = $1 AND status = 'PROSPECT' AND user_id IS NULL
+            RETURNING id`,
+			entitySlug, rec.EntityType, rec.Rating, rec.Reviews, profileDataJSON, source,
+		).Scan(&id)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// La valoración importada es la base; las reseñas propias se suman encima
+	if _, err := Conn.Exec(ctx, `SELECT refresh_entity_rating($1)`, id); err != nil {
+		log.Printf("⚠️ No se pudo recalcular la valoración de %s: %v", rec.Name, err)
+	}
+	return true, nil
+}
+
+// Log escribe el resumen de la importación, incluidos los registros a revisar
+func (r ImportReport) Log() {
+	mode := ""
+	if r.DryRun {
+		mode = " (dry-run: no se ha escrito nada)"
+	}
+	log.Printf("✅ Importación completada%s: %d registros, %d insertados, %d actualizados, %d omitidos, %d con error",
+		mode, r.Total, r.Inserted, r.Updated, len(r.Skipped), len(r.Failed))
+	log.Printf("   🕒 %d sin horario, %d horarios sin interpretar", r.WithoutHours, len(r.UnparsedHours))
+	for _, s := range r.Skipped {
+		log.Printf("   ⏭️ Omitido %s: %s", s.label(), s.Reason)
+	}
+	for _, f := range r.Failed {
+		log.Printf("   ❌ Error en %s: %s", f.label(), f.Reason)
+	}
+	for _, u := range r.UnparsedHours {
+		log.Printf("   ⚠️ Horario sin interpretar en %s: %q (%v)", u.Name, u.Text, u.Err)
+	}
+}
+
+// label identifica el registro en el informe: fila, nombre y slug si los hay
+func (i ImportIssue) label() string {
+	label := i.Name
+	if label == "" {
+		label = "(sin nombre)"
+	}
+	if i.Row > 0 {
+		label = fmt.Sprintf("fila %d, %s", i.Row, label)
+	}
+	if i.Slug != "" {
+		label += " [" + i.Slug + "]"
+	}
+	return label
+}
+
+// sanitizeCoord evita que el mapa reciba ceros o NaNs que lo bloquean
+func sanitizeCoord(v interface{}) float64 {
+	f := toFloat(v)
+	if f == 0 {
+		return 40.4167 // Madrid por defecto si no hay lat/lng
+	}
+	return f
+}
+
+func toFloat(v interface{}) float64 {
+	if v == nil {
+		return 0
+	}
+	switch t := v.(type) {
+	case float64:
+		return t
+	case float32:
+		return float64(t)
+	case int:
+		return float64(t)
+	case int64:
+		return float64(t)
+	case string:
+		var f float64
+		cleanStr := strings.Replace(t, ",", ".", -1)
+		fmt.Sscanf(cleanStr, "%f", &f)
+		return f
+	default:
+		return 0
+	}
+}
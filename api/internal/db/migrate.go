@@ -0,0 +1,282 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationLockKey identifica el advisory lock de las migraciones: si dos réplicas
+// arrancan a la vez, la segunda espera y encuentra todo aplicado
+const migrationLockKey int64 = 7_110_420_260_018
+
+var migrationFile = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+var (
+	ErrChecksumMismatch = errors.New("una migración aplicada ha cambiado")
+	ErrIrreversible     = errors.New("la migración no tiene .down.sql")
+)
+
+// Migration es una versión del esquema. El checksum es el del fichero up: si
+// cambia después de aplicarlo, Up se niega a continuar.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// MigrationStatus es una migración conocida (en el binario o en la base de datos)
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+	Changed   bool // El fichero up no coincide con el aplicado
+	Missing   bool // Aplicada en la base de datos pero ya no está en el binario
+}
+
+type Migrator struct {
+	Conn       *pgxpool.Pool
+	Migrations []Migration
+}
+
+func NewMigrator(conn *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{Conn: conn, Migrations: migrations}, nil
+}
+
+// LoadMigrations lee los ficheros NN_nombre.(up|down).sql ordenados por versión
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		m := migrationFile.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("nombre de migración no válido: %s (se espera NN_nombre.up.sql)", entry.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("versión %d duplicada: %s y %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			sum := sha256.Sum256(body)
+			mig.Up, mig.Checksum = string(body), hex.EncodeToString(sum[:])
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("la migración %02d_%s no tiene .up.sql", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// appliedMigration es una fila de schema_migrations
+type appliedMigration struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// withLock ejecuta fn en una conexión dedicada con el advisory lock tomado
+// (es de sesión, así que todo tiene que ir por la misma conexión)
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.Conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("error tomando el lock de migraciones: %v", err)
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	_, err = conn.Exec(ctx, `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version      integer     PRIMARY KEY,
+            name         text        NOT NULL,
+            checksum     text        NOT NULL,
+            applied_at   timestamptz NOT NULL DEFAULT now(),
+            execution_ms integer     NOT NULL DEFAULT 0
+        )`)
+	if err != nil {
+		return fmt.Errorf("error creando schema_migrations: %v", err)
+	}
+	return fn(conn)
+}
+
+func loadApplied(ctx context.Context, conn *pgxpool.Conn) (map[int]appliedMigration, error) {
+	rows, err := conn.Query(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]appliedMigration{}
+	for rows.Next() {
+		var version int
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = a
+	}
+	return applied, rows.Err()
+}
+
+// Up aplica en orden las migraciones pendientes, cada una en su transacción.
+// Devuelve las que ha aplicado.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.Migrations {
+			if a, ok := applied[mig.Version]; ok {
+				if a.checksum != mig.Checksum {
+					return fmt.Errorf("%w: %02d_%s", ErrChecksumMismatch, mig.Version, mig.Name)
+				}
+				continue
+			}
+
+			start := time.Now()
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, mig.Up); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `
+                    INSERT INTO schema_migrations (version, name, checksum, execution_ms)
+                    VALUES ($1, $2, $3, $4)`,
+					mig.Version, mig.Name, mig.Checksum, time.Since(start).Milliseconds())
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("error aplicando %02d_%s: %v", mig.Version, mig.Name, err)
+			}
+			log.Printf("🗄️ Migración %02d_%s aplicada (%s)", mig.Version, mig.Name, time.Since(start).Round(time.Millisecond))
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down deshace las últimas steps migraciones aplicadas, de la más reciente hacia atrás
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	byVersion := map[int]Migration{}
+	for _, mig := range m.Migrations {
+		byVersion[mig.Version] = mig
+	}
+
+	var done []Migration
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		versions := make([]int, 0, len(applied))
+		for v := range applied {
+			versions = append(versions, v)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+		for i, v := range versions {
+			if i >= steps {
+				break
+			}
+			mig, ok := byVersion[v]
+			if !ok {
+				return fmt.Errorf("la migración %02d_%s aplicada no está en este binario", v, applied[v].name)
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("%w: %02d_%s", ErrIrreversible, mig.Version, mig.Name)
+			}
+
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, mig.Down); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("error deshaciendo %02d_%s: %v", mig.Version, mig.Name, err)
+			}
+			log.Printf("↩️ Migración %02d_%s deshecha", mig.Version, mig.Name)
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status cruza las migraciones del binario con las aplicadas en la base de datos
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var status []MigrationStatus
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.Migrations {
+			s := MigrationStatus{Version: mig.Version, Name: mig.Name}
+			if a, ok := applied[mig.Version]; ok {
+				appliedAt := a.appliedAt
+				s.AppliedAt = &appliedAt
+				s.Changed = a.checksum != mig.Checksum
+				delete(applied, mig.Version)
+			}
+			status = append(status, s)
+		}
+		for v, a := range applied {
+			appliedAt := a.appliedAt
+			status = append(status, MigrationStatus{Version: v, Name: a.name, AppliedAt: &appliedAt, Missing: true})
+		}
+		sort.Slice(status, func(i, j int) bool { return status[i].Version < status[j].Version })
+		return nil
+	})
+	return status, err
+}
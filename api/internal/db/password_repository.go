@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+	"veterimap-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresPasswordRepository struct {
+	Conn *pgxpool.Pool
+}
+
+func NewPostgresPasswordRepository(db *pgxpool.Pool) *PostgresPasswordRepository {
+	return &PostgresPasswordRepository{Conn: db}
+}
+
+func (r *PostgresPasswordRepository) GetPasswordHash(ctx context.Context, userID uuid.UUID) (string, error) {
+	var hash string
+	err := r.Conn.QueryRow(ctx, `SELECT password FROM users WHERE id = $1`, userID).Scan(&hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", domain.ErrUserNotFound
+	}
+	return hash, err
+}
+
+func (r *PostgresPasswordRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	tag, err := r.Conn.Exec(ctx, `
+        UPDATE users SET password = $2, password_changed_at = NOW()
+        WHERE id = $1`, userID, passwordHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *PostgresPasswordRepository) PasswordChangedAt(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	var changedAt *time.Time
+	err := r.Conn.QueryRow(ctx, `SELECT password_changed_at FROM users WHERE id = $1`, userID).Scan(&changedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	}
+	return changedAt, err
+}
+
+func (r *PostgresPasswordRepository) CreatePasswordReset(ctx context.Context, userID uuid.UUID, tokenHash, ip string, expiresAt time.Time) error {
+	_, err := r.Conn.Exec(ctx, `
+        INSERT INTO password_resets (token_hash, user_id, ip, expires_at)
+        VALUES ($1, $2, $3, $4)`, tokenHash, userID, ip, expiresAt)
+	return err
+}
+
+func (r *PostgresPasswordRepository) CountRecentResets(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	var n int
+	err := r.Conn.QueryRow(ctx, `
+        SELECT COUNT(*) FROM password_resets WHERE user_id = $1 AND created_at >= $2`, userID, since).Scan(&n)
+	return n, err
+}
+
+func (r *PostgresPasswordRepository) ConsumePasswordReset(ctx context.Context, tokenHash, passwordHash string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := pgx.BeginFunc(ctx, r.Conn, func(tx pgx.Tx) error {
+		// El UPDATE con used_at IS NULL hace de bloqueo: dos usos simultáneos no pueden ganar ambos
+		err := tx.QueryRow(ctx, `
+            UPDATE password_resets SET used_at = NOW()
+            WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+            RETURNING user_id`, tokenHash).Scan(&userID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrResetTokenInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		// Los demás enlaces pendientes del usuario dejan de valer
+		_, err = tx.Exec(ctx, `
+            UPDATE password_resets SET used_at = NOW()
+            WHERE user_id = $1 AND used_at IS NULL`, userID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+            UPDATE users SET password = $2, password_changed_at = NOW()
+            WHERE id = $1`, userID, passwordHash)
+		return err
+	})
+	return userID, err
+}
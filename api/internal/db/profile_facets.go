@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"veterimap-api/internal/domain"
+)
+
+// maxFacetBuckets limita las facetas de valores abiertos (ciudades, especialidades)
+const maxFacetBuckets = 20
+
+// ratingBands son los cortes de la faceta de valoración, de mayor a menor
+var ratingBands = []float64{4.5, 4, 3}
+
+// FacetProfiles calcula los contadores del panel de filtros. Cada faceta parte del
+// filtro completo menos su propio criterio (p. ej. la de ciudades ignora f.City), así
+// cada opción muestra cuántos profesionales habría si el usuario la eligiera.
+func (r *PostgresProfileRepository) FacetProfiles(ctx context.Context, f domain.ProfileFilter) (*domain.ProfileFacets, error) {
+	facets := &domain.ProfileFacets{}
+	var err error
+
+	// 1. Tipo de ficha
+	ft := f
+	ft.EntityType = ""
+	q := newProfileQuery(ft)
+	facets.EntityType, err = r.facetBuckets(ctx, `
+        SELECT e.entity_type, COUNT(DISTINCT e.id)
+        FROM `+profileFrom+`
+        WHERE `+q.whereSQL()+`
+        GROUP BY e.entity_type
+        ORDER BY 2 DESC, 1`, q.args)
+	if err != nil {
+		return nil, fmt.Errorf("error en faceta de tipo: %v", err)
+	}
+
+	// 2. Especialidades (array JSONB de profile_data)
+	fs := f
+	fs.Specialty = ""
+	q = newProfileQuery(fs)
+	limit := q.arg(maxFacetBuckets)
+	facets.Specialty, err = r.facetBuckets(ctx, `
+        SELECT s.value, COUNT(DISTINCT e.id)
+        FROM `+profileFrom+`
+        CROSS JOIN LATERAL jsonb_array_elements_text(
+            CASE WHEN jsonb_typeof(e.profile_data->'specialties') = 'array'
+                 THEN e.profile_data->'specialties' ELSE '[]'::jsonb END
+        ) AS s(value)
+        WHERE `+q.whereSQL()+` AND s.value <> ''
+        GROUP BY s.value
+        ORDER BY 2 DESC, 1
+        LIMIT `+limit, q.args)
+	if err != nil {
+		return nil, fmt.Errorf("error en faceta de especialidad: %v", err)
+	}
+
+	// 3. Ciudades (de cualquiera de las direcciones del profesional)
+	fc := f
+	fc.City = ""
+	q = newProfileQuery(fc)
+	limit = q.arg(maxFacetBuckets)
+	facets.City, err = r.facetBuckets(ctx, `
+        SELECT l.city, COUNT(DISTINCT e.id)
+        FROM `+profileFrom+`
+        WHERE `+q.whereSQL()+` AND l.city <> ''
+        GROUP BY l.city
+        ORDER BY 2 DESC, 1
+        LIMIT `+limit, q.args)
+	if err != nil {
+		return nil, fmt.Errorf("error en faceta de ciudad: %v", err)
+	}
+
+	// 4. Valoración (tramos acumulados)
+	fr := f
+	fr.MinRating = 0
+	q = newProfileQuery(fr)
+	var bands []string
+	for _, band := range ratingBands {
+		bands = append(bands, fmt.Sprintf("COUNT(DISTINCT e.id) FILTER (WHERE e.rating >= %s)", q.arg(band)))
+	}
+	query := `
+        SELECT ` + strings.Join(bands, ",\n               ") + `
+        FROM ` + profileFrom + `
+        WHERE ` + q.whereSQL()
+	counts := make([]int, len(ratingBands))
+	dest := make([]interface{}, len(counts))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	if err := r.Conn.QueryRow(ctx, query, q.args...).Scan(dest...); err != nil {
+		return nil, fmt.Errorf("error en faceta de valoración: %v", err)
+	}
+	facets.Rating = make([]domain.FacetBucket, len(ratingBands))
+	for i, band := range ratingBands {
+		facets.Rating[i] = domain.FacetBucket{Value: strconv.FormatFloat(band, 'f', -1, 64), Count: counts[i]}
+	}
+
+	// 5. Acepta seguros
+	fi := f
+	fi.AcceptsInsurance = false
+	q = newProfileQuery(fi)
+	query = `
+        SELECT COUNT(DISTINCT e.id) FILTER (WHERE ` + acceptsInsuranceExpr + `)
+        FROM ` + profileFrom + `
+        WHERE ` + q.whereSQL()
+	if err := r.Conn.QueryRow(ctx, query, q.args...).Scan(&facets.AcceptsInsurance); err != nil {
+		return nil, fmt.Errorf("error en faceta de seguros: %v", err)
+	}
+
+	return facets, nil
+}
+
+// facetBuckets ejecuta una query de (valor, contador) y devuelve siempre un slice no nulo
+func (r *PostgresProfileRepository) facetBuckets(ctx context.Context, query string, args []interface{}) ([]domain.FacetBucket, error) {
+	rows, err := r.Conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []domain.FacetBucket{}
+	for rows.Next() {
+		var b domain.FacetBucket
+		if err := rows.Scan(&b.Value, &b.Count); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
@@ -0,0 +1,269 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/geo"
+)
+
+// profileQuery acumula condiciones y argumentos para las búsquedas de perfiles.
+// Cada filtro opcional solo añade su condición si viene informado, así el
+// planificador ve una query concreta y puede usar los índices (geo, texto...).
+type profileQuery struct {
+	conds []string
+	args  []interface{}
+
+	// tsQuery es el placeholder de la consulta de texto ("" si no hay); se reutiliza
+	// en el WHERE, el ranking y el resaltado
+	tsQuery string
+}
+
+// arg registra un argumento y devuelve su placeholder ($1, $2...)
+func (q *profileQuery) arg(v interface{}) string {
+	q.args = append(q.args, v)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+func (q *profileQuery) where(cond string) {
+	q.conds = append(q.conds, cond)
+}
+
+func (q *profileQuery) whereSQL() string {
+	if len(q.conds) == 0 {
+		return "true"
+	}
+	return strings.Join(q.conds, "\n          AND ")
+}
+
+// profileFrom une cada entidad con sus ubicaciones (alias e y l en todas las condiciones).
+// LEFT JOIN para que el listado siga mostrando fichas sin dirección; los filtros
+// de ciudad o geográficos descartan esas filas por sí solos.
+const profileFrom = `professional_entities e
+        LEFT JOIN professional_locations l ON l.entity_id = e.id`
+
+// newProfileQuery traduce un ProfileFilter a condiciones SQL sobre entidades (e) y ubicaciones (l)
+func newProfileQuery(f domain.ProfileFilter) *profileQuery {
+	q := &profileQuery{}
+	q.where("e.is_active = true")
+
+	if ts := prefixTsQuery(f.Query); ts != "" {
+		q.tsQuery = fmt.Sprintf("to_tsquery('es_unaccent', %s)", q.arg(ts))
+		q.where("e.search_vector @@ " + q.tsQuery)
+	}
+	if f.City != "" {
+		q.where("l.city ILIKE " + q.arg("%"+f.City+"%"))
+	}
+	if f.Tag != "" {
+		tag := q.arg(f.Tag)
+		q.where(fmt.Sprintf("(e.entity_type = %s OR e.profile_data->'specialties' @> jsonb_build_array(%s::text))", tag, tag))
+	}
+
+	if f.EntityType != "" {
+		q.where("e.entity_type = " + q.arg(f.EntityType))
+	}
+	if f.Specialty != "" {
+		q.where(fmt.Sprintf("e.profile_data->'specialties' @> jsonb_build_array(%s::text)", q.arg(f.Specialty)))
+	}
+	if f.MinRating > 0 {
+		q.where("e.rating >= " + q.arg(f.MinRating))
+	}
+	if f.AcceptsInsurance {
+		q.where(acceptsInsuranceExpr)
+	}
+
+	// Abierto en un instante: se traduce a día ISO, fecha y hora locales de Madrid
+	if f.OpenAt != nil {
+		local := f.OpenAt.In(domain.ScheduleLocation())
+		weekday := int(local.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
+		clock := q.arg(local.Format("15:04:05"))
+		q.where(fmt.Sprintf(`EXISTS (
+              SELECT 1 FROM professional_opening_hours oh
+              WHERE oh.entity_id = e.id AND oh.weekday = %s AND oh.opens <= %s::time AND %s::time < oh.closes)`,
+			q.arg(weekday), clock, clock))
+		q.where(fmt.Sprintf(`NOT EXISTS (
+              SELECT 1 FROM professional_holidays ph
+              WHERE ph.entity_id = e.id AND ph.day = %s::date)`, q.arg(local.Format("2006-01-02"))))
+	}
+
+	// Filtro espacial: el rectángulo usa el índice GiST sobre point(l.longitude, l.latitude)
+	if f.Bounds != nil {
+		q.whereInBox(f.Bounds.SouthWest.Lat, f.Bounds.SouthWest.Lng, f.Bounds.NorthEast.Lat, f.Bounds.NorthEast.Lng)
+	}
+	if f.Center != nil && f.RadiusKm > 0 {
+		// Pre-filtro por caja (indexado) + distancia exacta
+		q.whereInBox(geo.BoundsAround(f.Center.Lat, f.Center.Lng, f.RadiusKm))
+		q.where(fmt.Sprintf("haversine_km(%s, %s, l.latitude, l.longitude) <= %s",
+			q.arg(f.Center.Lat), q.arg(f.Center.Lng), q.arg(f.RadiusKm)))
+	}
+
+	return q
+}
+
+// acceptsInsuranceExpr compara como JSONB para no romper con valores que no sean booleanos
+const acceptsInsuranceExpr = `e.profile_data->'insurance_partners'->'accepts' = 'true'::jsonb`
+
+func (q *profileQuery) whereInBox(swLat, swLng, neLat, neLng float64) {
+	q.where(fmt.Sprintf("point(l.longitude, l.latitude) <@ box(point(%s, %s), point(%s, %s))",
+		q.arg(swLng), q.arg(swLat), q.arg(neLng), q.arg(neLat)))
+}
+
+// referencePoint es el punto desde el que se mide distance_km (centro o centro del viewport)
+func referencePoint(f domain.ProfileFilter) *domain.GeoPoint {
+	if f.Center != nil {
+		return f.Center
+	}
+	if f.Bounds != nil {
+		c := f.Bounds.Center()
+		return &c
+	}
+	return nil
+}
+
+// Relevancia: ranking de texto (0..1) modulado por la calidad del perfil. Un perfil
+// con 5 estrellas y 100+ reseñas conserva todo su ranking; uno sin reseñas, el 70%.
+const relevanceExpr = `ts_rank_cd(e.search_vector, %s, 32) *
+                (0.7 + 0.3 * (e.rating / 5.0) * LEAST(ln(1 + e.review_count) / ln(101), 1))`
+
+// Opciones de ts_headline para el snippet del listado
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=10, MaxFragments=1"
+
+// prefixTsQuery convierte el texto del usuario en una tsquery de prefijos:
+// "clinica sanch" -> "clinica:* & sanch:*". Solo deja pasar letras y dígitos,
+// así el usuario no puede inyectar operadores de tsquery.
+func prefixTsQuery(input string) string {
+	return weightedPrefixTsQuery(input, "")
+}
+
+// weightedPrefixTsQuery restringe además los prefijos a ciertos pesos del
+// search_vector ("A" = solo el nombre): "sanch" -> "sanch:*A"
+func weightedPrefixTsQuery(input, weights string) string {
+	words := strings.FieldsFunc(input, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, strings.ToLower(w)+":*"+weights)
+	}
+	return strings.Join(terms, " & ")
+}
+
+// likePrefix escapa los comodines de LIKE para buscar el texto como prefijo literal
+func likePrefix(input string) string {
+	r := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return r.Replace(input) + "%"
+}
+
+// sortKey es una columna de la subconsulta matches con el tipo con el que se compara
+type sortKey struct {
+	expr string
+	typ  string
+}
+
+// sql es la clave ya convertida: el ORDER BY, el cursor y la comparación usan la misma
+func (k sortKey) sql() string {
+	return fmt.Sprintf("(%s)::%s", k.expr, k.typ)
+}
+
+// profileSort define el ORDER BY de una ordenación. Todas las claves van en la misma
+// dirección y terminan en (id, address_index), así el orden es total y la página
+// siguiente se pide con una comparación de filas: (k1, k2, ...) > (cursor).
+type profileSort struct {
+	desc bool
+	keys []sortKey
+}
+
+var (
+	idKeys = []sortKey{{"id", "uuid"}, {"address_index", "int4"}}
+
+	profileSorts = map[string]profileSort{
+		domain.ProfileSortRelevance: {desc: true, keys: append([]sortKey{{"relevance", "float8"}, {"rating", "float8"}}, idKeys...)},
+		domain.ProfileSortDistance:  {keys: append([]sortKey{{"COALESCE(distance_km, 'Infinity')", "float8"}}, idKeys...)},
+		domain.ProfileSortRating:    {desc: true, keys: append([]sortKey{{"rating", "float8"}, {"review_count", "int8"}}, idKeys...)},
+		domain.ProfileSortReviews:   {desc: true, keys: append([]sortKey{{"review_count", "int8"}, {"rating", "float8"}}, idKeys...)},
+		domain.ProfileSortName:      {keys: append([]sortKey{{"name", "text"}}, idKeys...)},
+		domain.ProfileSortNewest:    {desc: true, keys: append([]sortKey{{"created_at", "timestamptz"}}, idKeys...)},
+	}
+)
+
+// resolveSort elige la ordenación por defecto: relevancia si hay texto, distancia si
+// hay punto de referencia y, si no, valoración
+func resolveSort(f domain.ProfileFilter) string {
+	switch {
+	case f.Sort != "":
+		return f.Sort
+	case prefixTsQuery(f.Query) != "":
+		return domain.ProfileSortRelevance
+	case referencePoint(f) != nil:
+		return domain.ProfileSortDistance
+	}
+	return domain.ProfileSortRating
+}
+
+func (s profileSort) orderBy() string {
+	dir := ""
+	if s.desc {
+		dir = " DESC"
+	}
+	parts := make([]string, len(s.keys))
+	for i, k := range s.keys {
+		parts[i] = k.sql() + dir
+	}
+	return strings.Join(parts, ", ")
+}
+
+// cursorExpr devuelve las claves de la fila como text[]: Postgres las serializa, así
+// el cursor vuelve a la query exactamente con el mismo valor (floats incluidos)
+func (s profileSort) cursorExpr() string {
+	parts := make([]string, len(s.keys))
+	for i, k := range s.keys {
+		parts[i] = k.sql() + "::text"
+	}
+	return "ARRAY[" + strings.Join(parts, ", ") + "]"
+}
+
+// afterCursor devuelve la condición "después del cursor" según la dirección del orden
+func (q *profileQuery) afterCursor(s profileSort, values []string) string {
+	cols := make([]string, len(s.keys))
+	params := make([]string, len(s.keys))
+	for i, k := range s.keys {
+		cols[i] = k.sql()
+		params[i] = fmt.Sprintf("%s::%s", q.arg(values[i]), k.typ)
+	}
+	op := ">"
+	if s.desc {
+		op = "<"
+	}
+	return fmt.Sprintf("(%s) %s (%s)", strings.Join(cols, ", "), op, strings.Join(params, ", "))
+}
+
+// profileCursor es el contenido del cursor opaco: ordenación y claves de la última fila
+type profileCursor struct {
+	Sort string   `json:"s"`
+	Keys []string `json:"k"`
+}
+
+func encodeCursor(sort string, keys []string) string {
+	raw, _ := json.Marshal(profileCursor{Sort: sort, Keys: keys})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor valida que el cursor corresponda a la ordenación pedida
+func decodeCursor(cursor, sort string) ([]string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	var c profileCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Sort != sort || len(c.Keys) != len(profileSorts[sort].keys) {
+		return nil, domain.ErrInvalidCursor
+	}
+	return c.Keys, nil
+}
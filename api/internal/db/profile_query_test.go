@@ -0,0 +1,58 @@
+package db
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"veterimap-api/internal/domain"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		sort string
+		keys []string
+	}{
+		{domain.ProfileSortRelevance, []string{"0.42", "4.5", "5b0c1d2e-0000-4000-8000-000000000001", "0"}},
+		{domain.ProfileSortDistance, []string{"Infinity", "5b0c1d2e-0000-4000-8000-000000000002", "1"}},
+		{domain.ProfileSortName, []string{"Clínica Veterinaria \"El Parque\", S.L.", "5b0c1d2e-0000-4000-8000-000000000003", "0"}},
+		{domain.ProfileSortNewest, []string{"2024-05-01T10:00:00Z", "5b0c1d2e-0000-4000-8000-000000000004", "2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			got, err := decodeCursor(encodeCursor(tt.sort, tt.keys), tt.sort)
+			if err != nil {
+				t.Fatalf("decodeCursor: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.keys) {
+				t.Errorf("claves = %q, se esperaba %q", got, tt.keys)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	nameKeys := []string{"Clínica", "5b0c1d2e-0000-4000-8000-000000000001", "0"}
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   string
+	}{
+		{"no es base64", "%%%", domain.ProfileSortName},
+		{"no es JSON", base64.RawURLEncoding.EncodeToString([]byte("hola")), domain.ProfileSortName},
+		{"de otra ordenación", encodeCursor(domain.ProfileSortName, nameKeys), domain.ProfileSortRating},
+		{"faltan claves", encodeCursor(domain.ProfileSortName, nameKeys[:2]), domain.ProfileSortName},
+		{"sobran claves", encodeCursor(domain.ProfileSortName, append(nameKeys, "x")), domain.ProfileSortName},
+		{"vacío", "", domain.ProfileSortName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.cursor, tt.sort); !errors.Is(err, domain.ErrInvalidCursor) {
+				t.Errorf("error = %v, se esperaba ErrInvalidCursor", err)
+			}
+		})
+	}
+}
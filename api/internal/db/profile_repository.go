@@ -3,11 +3,14 @@ package db
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 	"veterimap-api/internal/domain"
 
 	"github.com/google/uuid"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -19,68 +22,128 @@ func NewPostgresProfileRepository(db *pgxpool.Pool) *PostgresProfileRepository {
 	return &PostgresProfileRepository{Conn: db}
 }
 
-// SearchProfiles: Motor de búsqueda unificado para mapa y listados
-func (r *PostgresProfileRepository) SearchProfiles(ctx context.Context, name, city, tag string, limit, offset int) (int, []domain.ProfileSummary, error) {
-	var profiles []domain.ProfileSummary
-	var totalCount int
+// SearchProfiles: Motor de búsqueda unificado para mapa y listados.
+// Busca sobre todas las direcciones de cada profesional (professional_locations).
+// Si el filtro trae bounding box o centro+radio, rellena DistanceKm. La paginación
+// es por cursor (keyset) sobre la ordenación pedida; Offset solo se usa sin cursor.
+func (r *PostgresProfileRepository) SearchProfiles(ctx context.Context, f domain.ProfileFilter) (*domain.ProfilePage, error) {
+	page := &domain.ProfilePage{}
 
-	// Preparamos los parámetros para ILIKE
-	nameParam := "%" + name + "%"
-	cityParam := "%" + city + "%"
+	q := newProfileQuery(f)
+	if f.PerLocation {
+		q.where("l.latitude IS NOT NULL AND l.longitude IS NOT NULL")
+	}
 
-	// 1. QUERY DE CONTEO
-	// Nota: En Go, para pasar un '%' literal en una string que usa placeholders, se usa '%%'
+	// 1. QUERY DE CONTEO (pines o profesionales distintos según el modo)
+	countExpr := "COUNT(DISTINCT e.id)"
+	if f.PerLocation {
+		countExpr = "COUNT(*)"
+	}
 	countQuery := `
-        SELECT COUNT(*) 
-        FROM professional_entities 
-        WHERE is_active = true
-          AND ($1 = '%%' OR name ILIKE $1)
-          AND ($2 = '%%' OR profile_data->'addresses'->0->>'city' ILIKE $2)
-          AND (
-               $3 = '' OR 
-               entity_type = $3 OR 
-               profile_data->'specialties' @> jsonb_build_array($3)
-          )`
-
-	err := r.Conn.QueryRow(ctx, countQuery, nameParam, cityParam, tag).Scan(&totalCount)
+        SELECT ` + countExpr + `
+        FROM ` + profileFrom + `
+        WHERE ` + q.whereSQL()
+
+	err := r.Conn.QueryRow(ctx, countQuery, q.args...).Scan(&page.Total)
 	if err != nil {
-		return 0, nil, fmt.Errorf("error counting profiles: %v", err)
+		return nil, fmt.Errorf("error counting profiles: %v", err)
 	}
 
 	// 2. QUERY DE DATOS
-	// Corregimos la extracción de latitude/longitude.
-	// Al usar ->> extraemos texto, por lo que casteamos a NUMERIC para el Scan a float64.
+	sortName := resolveSort(f)
+	sort, ok := profileSorts[sortName]
+	if !ok {
+		return nil, fmt.Errorf("ordenación desconocida: %s", sortName)
+	}
+	if sortName == domain.ProfileSortRelevance && q.tsQuery == "" {
+		return nil, fmt.Errorf("la ordenación por relevancia requiere búsqueda de texto")
+	}
+
+	// La distancia se calcula respecto al centro del radio o del viewport
+	distanceExpr := "NULL::double precision"
+	relevance := "NULL::double precision"
+	snippetExpr := "''"
+
+	if q.tsQuery != "" {
+		relevance = fmt.Sprintf(relevanceExpr, q.tsQuery)
+		snippetExpr = fmt.Sprintf("ts_headline('es_unaccent', COALESCE(NULLIF(bio, ''), name), %s, %s)",
+			q.tsQuery, q.arg(headlineOptions))
+	}
+	if ref := referencePoint(f); ref != nil {
+		distanceExpr = fmt.Sprintf("haversine_km(%s, %s, l.latitude, l.longitude)", q.arg(ref.Lat), q.arg(ref.Lng))
+	}
+
+	// Página siguiente: filas estrictamente posteriores a la última entregada
+	after := "true"
+	offset := "0"
+	if f.Cursor != "" {
+		keys, err := decodeCursor(f.Cursor, sortName)
+		if err != nil {
+			return nil, err
+		}
+		after = q.afterCursor(sort, keys)
+	} else {
+		offset = q.arg(f.Offset)
+	}
+	// Una fila de más para saber si existe página siguiente
+	limit := q.arg(f.Limit + 1)
+
+	// Sin PerLocation nos quedamos con una fila por profesional: la ubicación más
+	// cercana si hay referencia y, a igualdad, la principal
+	distinct, innerOrder := "", ""
+	if !f.PerLocation {
+		distinct = "DISTINCT ON (e.id)"
+		innerOrder = "ORDER BY e.id, distance_km ASC NULLS LAST, l.is_main DESC NULLS LAST, l.address_index"
+	}
+
+	// El resaltado (ts_headline) es caro: solo se calcula para la página ya recortada
 	dataQuery := `
-        SELECT 
-            id, 
-            name, 
-            entity_type, 
-            rating, 
-            review_count,
-            COALESCE(profile_data->'addresses'->0->>'city', '') as city,
-            COALESCE(profile_data->'addresses'->0->>'full_address', '') as full_address,
-            COALESCE((profile_data->'addresses'->0->>'latitude')::numeric, 0) as lat,
-            COALESCE((profile_data->'addresses'->0->>'longitude')::numeric, 0) as lng
-        FROM professional_entities
-        WHERE is_active = true
-          AND ($1 = '%%' OR name ILIKE $1)
-          AND ($2 = '%%' OR profile_data->'addresses'->0->>'city' ILIKE $2)
-          AND (
-               $3 = '' OR 
-               entity_type = $3 OR 
-               profile_data->'specialties' @> jsonb_build_array($3)
-          )
-        ORDER BY rating DESC, review_count DESC
-        LIMIT $4 OFFSET $5`
-
-	rows, err := r.Conn.Query(ctx, dataQuery, nameParam, cityParam, tag, limit, offset)
+        SELECT id, name, entity_type, rating, review_count, city, full_address, lat, lng,
+               address_index, is_main, distance_km, ` + snippetExpr + ` as snippet,
+               working_hours, holidays, cursor_key
+        FROM (
+            SELECT *, ` + sort.cursorExpr() + ` as cursor_key
+            FROM (
+                SELECT ` + distinct + `
+                    e.id, 
+                    e.name, 
+                    e.entity_type, 
+                    e.rating, 
+                    e.review_count,
+                    e.created_at,
+                    COALESCE(l.city, '') as city,
+                    COALESCE(l.full_address, '') as full_address,
+                    COALESCE(l.latitude, 0) as lat,
+                    COALESCE(l.longitude, 0) as lng,
+                    COALESCE(l.address_index, 0) as address_index,
+                    COALESCE(l.is_main, false) as is_main,
+                    ` + distanceExpr + ` as distance_km,
+                    ` + relevance + ` as relevance,
+                    COALESCE(e.profile_data->>'bio', '') as bio,
+                    e.profile_data->'working_hours' as working_hours,
+                    e.profile_data->'holidays' as holidays
+                FROM ` + profileFrom + `
+                WHERE ` + q.whereSQL() + `
+                ` + innerOrder + `
+            ) matches
+            WHERE ` + after + `
+            ORDER BY ` + sort.orderBy() + `
+            LIMIT ` + limit + ` OFFSET ` + offset + `
+        ) page
+        ORDER BY ` + sort.orderBy()
+
+	rows, err := r.Conn.Query(ctx, dataQuery, q.args...)
 	if err != nil {
-		return 0, nil, err
+		return nil, err
 	}
 	defer rows.Close()
 
+	now := time.Now()
+	var cursorKeys [][]string
 	for rows.Next() {
 		var p domain.ProfileSummary
+		var hoursJSON, holidaysJSON []byte
+		var keys []string
 
 		err := rows.Scan(
 			&p.ID,
@@ -92,17 +155,182 @@ func (r *PostgresProfileRepository) SearchProfiles(ctx context.Context, name, ci
 			&p.FullAddress,
 			&p.Latitude,
 			&p.Longitude,
+			&p.AddressIndex,
+			&p.IsMain,
+			&p.DistanceKm,
+			&p.Snippet,
+			&hoursJSON,
+			&holidaysJSON,
+			&keys,
 		)
 		if err != nil {
 			fmt.Printf("⚠️ Error scanneando perfil ID %s: %v\n", p.ID, err)
 			continue
 		}
+		fillOpeningStatus(&p, hoursJSON, holidaysJSON, now)
+		page.Profiles = append(page.Profiles, p)
+		cursorKeys = append(cursorKeys, keys)
+	}
+
+	if f.Limit > 0 && len(page.Profiles) > f.Limit {
+		page.Profiles = page.Profiles[:f.Limit]
+		page.NextCursor = encodeCursor(sortName, cursorKeys[f.Limit-1])
+	}
+	return page, nil
+}
+
+// fillOpeningStatus calcula is_open_now y next_opening a partir del JSONB del horario.
+// Los horarios con formato antiguo o ilegible dejan ambos campos vacíos (desconocido).
+func fillOpeningStatus(p *domain.ProfileSummary, hoursJSON, holidaysJSON []byte, now time.Time) {
+	var schedule domain.Schedule
+	if len(hoursJSON) == 0 || json.Unmarshal(hoursJSON, &schedule.Hours) != nil {
+		return
+	}
+	if len(holidaysJSON) > 0 {
+		_ = json.Unmarshal(holidaysJSON, &schedule.Holidays)
+	}
+	if !schedule.Known() {
+		return
+	}
+
+	open := schedule.IsOpenAt(now)
+	p.IsOpenNow = &open
+	p.NextOpening = schedule.NextOpening(now)
+}
+
+// ClusterProfiles agrupa en una rejilla de cellSizeDeg grados las ubicaciones que cumplen el filtro.
+// El tamaño de la respuesta depende del número de celdas visibles, no del número de clínicas.
+func (r *PostgresProfileRepository) ClusterProfiles(ctx context.Context, f domain.ProfileFilter, cellSizeDeg float64) ([]domain.ProfileCluster, error) {
+	q := newProfileQuery(f)
+	q.where("l.latitude IS NOT NULL AND l.longitude IS NOT NULL")
+	cell := q.arg(cellSizeDeg)
+
+	query := `
+        SELECT 
+            COUNT(*),
+            AVG(l.latitude), AVG(l.longitude),
+            MIN(l.latitude), MIN(l.longitude),
+            MAX(l.latitude), MAX(l.longitude),
+            mode() WITHIN GROUP (ORDER BY e.entity_type)
+        FROM ` + profileFrom + `
+        WHERE ` + q.whereSQL() + `
+        GROUP BY floor(l.longitude / ` + cell + `), floor(l.latitude / ` + cell + `)
+        ORDER BY COUNT(*) DESC
+        LIMIT ` + q.arg(f.Limit)
+
+	rows, err := r.Conn.Query(ctx, query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("error agrupando perfiles: %v", err)
+	}
+	defer rows.Close()
+
+	var clusters []domain.ProfileCluster
+	for rows.Next() {
+		var c domain.ProfileCluster
+		err := rows.Scan(
+			&c.Count,
+			&c.Latitude, &c.Longitude,
+			&c.Bounds.SouthWest.Lat, &c.Bounds.SouthWest.Lng,
+			&c.Bounds.NorthEast.Lat, &c.Bounds.NorthEast.Lng,
+			&c.EntityType,
+		)
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, c)
+	}
+	return clusters, rows.Err()
+}
+
+// TileProfiles devuelve los pines (uno por ubicación) de una tesela: sin conteo ni
+// distancia, solo lo que necesita el codificador MVT. Con tope, se conservan los mejor valorados.
+func (r *PostgresProfileRepository) TileProfiles(ctx context.Context, f domain.ProfileFilter) ([]domain.ProfileSummary, error) {
+	q := newProfileQuery(f)
+	q.where("l.latitude IS NOT NULL AND l.longitude IS NOT NULL")
+
+	query := `
+        SELECT e.id, e.name, e.entity_type, e.rating, e.review_count, l.latitude, l.longitude, l.address_index, l.is_main
+        FROM ` + profileFrom + `
+        WHERE ` + q.whereSQL() + `
+        ORDER BY e.rating DESC, e.review_count DESC, e.id, l.address_index
+        LIMIT ` + q.arg(f.Limit)
+
+	rows, err := r.Conn.Query(ctx, query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("error cargando tesela: %v", err)
+	}
+	defer rows.Close()
+
+	var profiles []domain.ProfileSummary
+	for rows.Next() {
+		var p domain.ProfileSummary
+		err := rows.Scan(&p.ID, &p.Name, &p.ProfileType, &p.Rating, &p.ReviewCount,
+			&p.Latitude, &p.Longitude, &p.AddressIndex, &p.IsMain)
+		if err != nil {
+			return nil, err
+		}
 		profiles = append(profiles, p)
 	}
-	return totalCount, profiles, nil
+	return profiles, rows.Err()
+}
+
+// Suggest resuelve el autocompletado en un único viaje a la base de datos:
+// clínicas por nombre, ciudades con su nº de profesionales y especialidades.
+// Cada bloque tiene su propio límite para que un tipo no acapare la lista.
+func (r *PostgresProfileRepository) Suggest(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error) {
+	nameQuery := weightedPrefixTsQuery(prefix, "A")
+	if nameQuery == "" {
+		return nil, nil
+	}
+
+	query := `
+        (SELECT 'clinic', e.name, e.id::text, e.entity_type,
+                COALESCE((SELECT l.city FROM professional_locations l
+                          WHERE l.entity_id = e.id ORDER BY l.is_main DESC, l.address_index LIMIT 1), ''),
+                0
+         FROM professional_entities e
+         WHERE e.is_active = true AND e.search_vector @@ to_tsquery('es_unaccent', $1)
+         ORDER BY ts_rank(e.search_vector, to_tsquery('es_unaccent', $1)) DESC, e.rating DESC
+         LIMIT $3)
+        UNION ALL
+        (SELECT 'city', MIN(l.city), '', '', '', COUNT(DISTINCT l.entity_id)::int
+         FROM professional_locations l
+         JOIN professional_entities e ON e.id = l.entity_id AND e.is_active = true
+         WHERE lower(immutable_unaccent(l.city)) LIKE lower(immutable_unaccent($2))
+         GROUP BY lower(immutable_unaccent(l.city))
+         ORDER BY COUNT(DISTINCT l.entity_id) DESC
+         LIMIT $3)
+        UNION ALL
+        (SELECT 'specialty', MIN(s.tag), '', '', '', COUNT(DISTINCT e.id)::int
+         FROM professional_entities e
+         CROSS JOIN LATERAL jsonb_array_elements_text(
+             CASE WHEN jsonb_typeof(e.profile_data->'specialties') = 'array'
+                  THEN e.profile_data->'specialties' ELSE '[]'::jsonb END) AS s(tag)
+         WHERE e.is_active = true AND lower(immutable_unaccent(s.tag)) LIKE lower(immutable_unaccent($2))
+         GROUP BY lower(immutable_unaccent(s.tag))
+         ORDER BY COUNT(DISTINCT e.id) DESC
+         LIMIT $3)`
+
+	rows, err := r.Conn.Query(ctx, query, nameQuery, likePrefix(prefix), limit)
+	if err != nil {
+		return nil, fmt.Errorf("error en sugerencias: %v", err)
+	}
+	defer rows.Close()
+
+	var suggestions []domain.Suggestion
+	for rows.Next() {
+		var s domain.Suggestion
+		if err := rows.Scan(&s.Type, &s.Label, &s.ID, &s.EntityType, &s.City, &s.Count); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, rows.Err()
 }
 
-// GetProfileDetail: Obtiene la ficha completa decodificando el JSONB automáticamente
+// GetProfileDetail: Obtiene la ficha completa decodificando el JSONB automáticamente.
+// Solo fichas activas: las pendientes de la primera aprobación y las desactivadas
+// por un admin no son públicas (ErrProfileNotFound).
 func (r *PostgresProfileRepository) GetProfileDetail(ctx context.Context, id string) (*domain.ProfileDetail, error) {
 	// IMPORTANTE: id, user_id, entity_type, status, name, slug, rating, review_count, is_active son columnas reales.
 	// profile_data es la columna JSONB que mapea al struct anidado.
@@ -114,7 +342,7 @@ func (r *PostgresProfileRepository) GetProfileDetail(ctx context.Context, id str
 			u.trial_ends_at
 		FROM professional_entities p
 		LEFT JOIN users u ON p.user_id = u.id
-		WHERE p.id = $1`
+		WHERE p.id = $1 AND p.is_active`
 
 	var d domain.ProfileDetail
 	var tempUser domain.User
@@ -136,6 +364,9 @@ func (r *PostgresProfileRepository) GetProfileDetail(ctx context.Context, id str
 		&d.TrialEndsAt,
 	)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrProfileNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error obteniendo detalle de perfil: %v", err)
 	}
@@ -147,18 +378,48 @@ func (r *PostgresProfileRepository) GetProfileDetail(ctx context.Context, id str
 	return &d, nil
 }
 
+// ResolveSlug busca el slug entre los vigentes y, si no, entre las redirecciones.
+// Si current difiere del slug pedido, el handler responde con un 301. Como en el
+// listado y el mapa, las fichas inactivas no existen (ErrProfileNotFound).
+func (r *PostgresProfileRepository) ResolveSlug(ctx context.Context, slug string) (string, string, error) {
+	query := `
+		SELECT id::text, slug FROM (
+			SELECT e.id, e.slug, 1 AS priority
+			FROM professional_entities e
+			WHERE e.slug = $1 AND e.is_active
+			UNION ALL
+			SELECT e.id, e.slug, 2 AS priority
+			FROM professional_slug_history h
+			JOIN professional_entities e ON e.id = h.entity_id
+			WHERE h.slug = $1 AND e.is_active
+		) found
+		ORDER BY priority
+		LIMIT 1`
+
+	var id, current string
+	err := r.Conn.QueryRow(ctx, query, slug).Scan(&id, &current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", "", domain.ErrProfileNotFound
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("error resolviendo slug: %v", err)
+	}
+	return id, current, nil
+}
+
 func (r *PostgresProfileRepository) UpsertProfessionalProfile(ctx context.Context, p *domain.ProfessionalEntity) error {
 	query := `
 		INSERT INTO professional_entities (
 			user_id, entity_type, status, name, slug, profile_data, is_active, updated_at
 		) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		VALUES ($1, $2, $3, $4, unique_slug($5, (SELECT id FROM professional_entities WHERE user_id = $1)), $6, $7, NOW())
 		ON CONFLICT (user_id) DO UPDATE SET
 			entity_type = EXCLUDED.entity_type,
 			name = EXCLUDED.name,
+			slug = COALESCE(EXCLUDED.slug, professional_entities.slug),
 			profile_data = EXCLUDED.profile_data,
 			updated_at = NOW()
-		RETURNING id, created_at`
+		RETURNING id, slug, created_at`
 
 	// En Go, para pasar un struct a JSONB en Postgres, lo serializamos primero
 	profileDataJSON, err := json.Marshal(p.ProfileData)
@@ -174,7 +435,7 @@ func (r *PostgresProfileRepository) UpsertProfessionalProfile(ctx context.Contex
 		p.Slug,
 		profileDataJSON,
 		p.IsActive,
-	).Scan(&p.ID, &p.CreatedAt)
+	).Scan(&p.ID, &p.Slug, &p.CreatedAt)
 
 	if err != nil {
 		fmt.Printf("❌ Error en UpsertProfessionalProfile: %v\n", err)
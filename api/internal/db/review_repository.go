@@ -0,0 +1,261 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"veterimap-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresReviewRepository struct {
+	Conn *pgxpool.Pool
+}
+
+func NewPostgresReviewRepository(db *pgxpool.Pool) *PostgresReviewRepository {
+	return &PostgresReviewRepository{Conn: db}
+}
+
+// Del autor solo se publica el nombre de pila
+const reviewColumns = `
+        rv.id, rv.entity_id, rv.appointment_id, rv.author_id,
+        COALESCE(NULLIF(split_part(COALESCE(u.name, ''), ' ', 1), ''), 'Anónimo'),
+        rv.stars, rv.body, rv.status, rv.reply, rv.replied_at, rv.created_at, rv.updated_at
+    FROM reviews rv
+    JOIN users u ON u.id = rv.author_id`
+
+func scanReview(row pgx.Row) (*domain.Review, error) {
+	var rv domain.Review
+	err := row.Scan(
+		&rv.ID, &rv.EntityID, &rv.AppointmentID, &rv.AuthorID, &rv.AuthorName,
+		&rv.Stars, &rv.Body, &rv.Status, &rv.Reply, &rv.RepliedAt, &rv.CreatedAt, &rv.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rv, nil
+}
+
+func (r *PostgresReviewRepository) GetAppointment(ctx context.Context, id uuid.UUID) (*domain.Appointment, error) {
+	var a domain.Appointment
+	err := r.Conn.QueryRow(ctx, `
+        SELECT id, professional_id, owner_id, pet_id, appointment_date, status, COALESCE(notes, ''), created_at
+        FROM appointments WHERE id = $1`, id).
+		Scan(&a.ID, &a.ProfessionalID, &a.OwnerID, &a.PetID, &a.AppointmentDate, &a.Status, &a.Notes, &a.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrReviewNotAllowed
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *PostgresReviewRepository) CreateReview(ctx context.Context, rv *domain.Review) error {
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+        INSERT INTO reviews (id, entity_id, appointment_id, author_id, stars, body, status)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING created_at, updated_at`,
+		rv.ID, rv.EntityID, rv.AppointmentID, rv.AuthorID, rv.Stars, rv.Body, rv.Status,
+	).Scan(&rv.CreatedAt, &rv.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrReviewDuplicate
+		}
+		return fmt.Errorf("error guardando reseña: %v", err)
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT refresh_entity_rating($1)`, rv.EntityID); err != nil {
+		return fmt.Errorf("error recalculando valoración: %v", err)
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresReviewRepository) GetReview(ctx context.Context, id uuid.UUID) (*domain.Review, error) {
+	rv, err := scanReview(r.Conn.QueryRow(ctx, `SELECT `+reviewColumns+` WHERE rv.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrReviewNotFound
+	}
+	return rv, err
+}
+
+// ListPublishedReviews pagina las reseñas visibles de una ficha, las más recientes primero
+func (r *PostgresReviewRepository) ListPublishedReviews(ctx context.Context, entityID uuid.UUID, limit, offset int) (int, []domain.Review, error) {
+	var total int
+	err := r.Conn.QueryRow(ctx, `SELECT COUNT(*) FROM reviews WHERE entity_id = $1 AND status = 'PUBLISHED'`, entityID).Scan(&total)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rows, err := r.Conn.Query(ctx, `
+        SELECT `+reviewColumns+`
+        WHERE rv.entity_id = $1 AND rv.status = 'PUBLISHED'
+        ORDER BY rv.created_at DESC, rv.id
+        LIMIT $2 OFFSET $3`, entityID, limit, offset)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	reviews := []domain.Review{}
+	for rows.Next() {
+		rv, err := scanReview(rows)
+		if err != nil {
+			return 0, nil, err
+		}
+		reviews = append(reviews, *rv)
+	}
+	return total, reviews, rows.Err()
+}
+
+// SetReply guarda la respuesta del profesional; solo se admite una
+func (r *PostgresReviewRepository) SetReply(ctx context.Context, reviewID uuid.UUID, reply string) error {
+	tag, err := r.Conn.Exec(ctx, `
+        UPDATE reviews SET reply = $2, replied_at = NOW(), updated_at = NOW()
+        WHERE id = $1 AND reply IS NULL`, reviewID, reply)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrReviewAlreadyReplied
+	}
+	return nil
+}
+
+func (r *PostgresReviewRepository) ReportReview(ctx context.Context, reviewID, reporterID uuid.UUID, reason string, hideThreshold int) (bool, error) {
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO review_reports (review_id, reporter_id, reason) VALUES ($1, $2, $3)`,
+		reviewID, reporterID, reason)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return false, domain.ErrReviewAlreadyReport
+		}
+		return false, fmt.Errorf("error guardando reporte: %v", err)
+	}
+
+	// Al llegar al umbral se oculta hasta que la revise un moderador
+	var entityID uuid.UUID
+	var hidden bool
+	err = tx.QueryRow(ctx, `
+        UPDATE reviews
+        SET report_count = report_count + 1,
+            status = CASE WHEN report_count + 1 >= $2 AND moderated_at IS NULL THEN 'HIDDEN' ELSE status END,
+            updated_at = NOW()
+        WHERE id = $1
+        RETURNING entity_id, status = 'HIDDEN' AND moderated_at IS NULL`, reviewID, hideThreshold).Scan(&entityID, &hidden)
+	if err != nil {
+		return false, err
+	}
+
+	if hidden {
+		if _, err := tx.Exec(ctx, `SELECT refresh_entity_rating($1)`, entityID); err != nil {
+			return false, fmt.Errorf("error recalculando valoración: %v", err)
+		}
+	}
+	return hidden, tx.Commit(ctx)
+}
+
+// ListReviewsByStatus es la cola de moderación: primero las que nadie ha revisado
+func (r *PostgresReviewRepository) ListReviewsByStatus(ctx context.Context, status domain.ReviewStatus, limit, offset int) (int, []domain.ReportedReview, error) {
+	var total int
+	err := r.Conn.QueryRow(ctx, `SELECT COUNT(*) FROM reviews WHERE status = $1`, status).Scan(&total)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rows, err := r.Conn.Query(ctx, `
+        SELECT `+reviewColumns+`
+        WHERE rv.status = $1
+        ORDER BY rv.moderated_at IS NOT NULL, rv.updated_at DESC, rv.id
+        LIMIT $2 OFFSET $3`, status, limit, offset)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	reviews := []domain.ReportedReview{}
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		rv, err := scanReview(rows)
+		if err != nil {
+			return 0, nil, err
+		}
+		reviews = append(reviews, domain.ReportedReview{Review: *rv, ReportReasons: []string{}})
+		ids = append(ids, rv.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	if len(ids) == 0 {
+		return total, reviews, nil
+	}
+
+	// Recuento, motivos y decisión del moderador, en una sola consulta para la página
+	rows, err = r.Conn.Query(ctx, `
+        SELECT rv.id, rv.report_count, rv.moderated_by, rv.moderated_at,
+               COALESCE(array_agg(rr.reason ORDER BY rr.created_at) FILTER (WHERE rr.reason IS NOT NULL), '{}')
+        FROM reviews rv
+        LEFT JOIN review_reports rr ON rr.review_id = rv.id
+        WHERE rv.id = ANY($1)
+        GROUP BY rv.id`, ids)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	byID := make(map[uuid.UUID]*domain.ReportedReview, len(reviews))
+	for i := range reviews {
+		byID[reviews[i].ID] = &reviews[i]
+	}
+	for rows.Next() {
+		var id uuid.UUID
+		var rr domain.ReportedReview
+		if err := rows.Scan(&id, &rr.ReportCount, &rr.ModeratedBy, &rr.ModeratedAt, &rr.ReportReasons); err != nil {
+			return 0, nil, err
+		}
+		if target, ok := byID[id]; ok {
+			target.ReportCount, target.ModeratedBy, target.ModeratedAt, target.ReportReasons =
+				rr.ReportCount, rr.ModeratedBy, rr.ModeratedAt, rr.ReportReasons
+		}
+	}
+	return total, reviews, rows.Err()
+}
+
+func (r *PostgresReviewRepository) ModerateReview(ctx context.Context, reviewID, adminID uuid.UUID, status domain.ReviewStatus) error {
+	return pgx.BeginFunc(ctx, r.Conn, func(tx pgx.Tx) error {
+		var entityID uuid.UUID
+		err := tx.QueryRow(ctx, `
+            UPDATE reviews
+            SET status = $2, moderated_by = $3, moderated_at = NOW(), updated_at = NOW()
+            WHERE id = $1
+            RETURNING entity_id`, reviewID, status, adminID).Scan(&entityID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrReviewNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `SELECT refresh_entity_rating($1)`, entityID); err != nil {
+			return fmt.Errorf("error recalculando valoración: %v", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"veterimap-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresRevisionRepository struct {
+	Conn *pgxpool.Pool
+}
+
+func NewPostgresRevisionRepository(db *pgxpool.Pool) *PostgresRevisionRepository {
+	return &PostgresRevisionRepository{Conn: db}
+}
+
+const revisionColumns = `
+        id, entity_id, user_id, name, entity_type, profile_data, changes, flags,
+        status, reason, reviewed_by, reviewed_at, created_at
+    FROM profile_revisions`
+
+func scanRevision(row pgx.Row) (*domain.ProfileRevision, error) {
+	var rev domain.ProfileRevision
+	err := row.Scan(
+		&rev.ID, &rev.EntityID, &rev.UserID, &rev.Name, &rev.EntityType, &rev.ProfileData, &rev.Changes, &rev.Flags,
+		&rev.Status, &rev.Reason, &rev.ReviewedBy, &rev.ReviewedAt, &rev.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+func (r *PostgresRevisionRepository) CreateRevision(ctx context.Context, rev *domain.ProfileRevision) error {
+	dataJSON, err := json.Marshal(rev.ProfileData)
+	if err != nil {
+		return fmt.Errorf("error serializing profile data: %v", err)
+	}
+
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Solo una pendiente por ficha: el último envío sustituye al anterior
+	_, err = tx.Exec(ctx, `
+        UPDATE profile_revisions SET status = 'SUPERSEDED'
+        WHERE entity_id = $1 AND status = 'PENDING'`, rev.EntityID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx, `
+        INSERT INTO profile_revisions (id, entity_id, user_id, name, entity_type, profile_data, changes, flags, status)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        RETURNING created_at`,
+		rev.ID, rev.EntityID, rev.UserID, rev.Name, rev.EntityType, dataJSON, rev.Changes, rev.Flags, rev.Status,
+	).Scan(&rev.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error guardando revisión: %v", err)
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRevisionRepository) DiscardPendingRevision(ctx context.Context, entityID uuid.UUID) error {
+	_, err := r.Conn.Exec(ctx, `
+        UPDATE profile_revisions SET status = 'SUPERSEDED'
+        WHERE entity_id = $1 AND status = 'PENDING'`, entityID)
+	return err
+}
+
+func (r *PostgresRevisionRepository) GetRevision(ctx context.Context, id uuid.UUID) (*domain.ProfileRevision, error) {
+	rev, err := scanRevision(r.Conn.QueryRow(ctx, `SELECT `+revisionColumns+` WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+func (r *PostgresRevisionRepository) GetLatestRevision(ctx context.Context, entityID uuid.UUID) (*domain.ProfileRevision, error) {
+	rev, err := scanRevision(r.Conn.QueryRow(ctx, `
+        SELECT `+revisionColumns+`
+        WHERE entity_id = $1 AND status <> 'SUPERSEDED'
+        ORDER BY created_at DESC LIMIT 1`, entityID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+// ListRevisions pagina la cola de moderación: primero las marcadas, después las más antiguas
+func (r *PostgresRevisionRepository) ListRevisions(ctx context.Context, status domain.RevisionStatus, limit, offset int) (int, []domain.ProfileRevision, error) {
+	var total int
+	err := r.Conn.QueryRow(ctx, `SELECT COUNT(*) FROM profile_revisions WHERE status = $1`, status).Scan(&total)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rows, err := r.Conn.Query(ctx, `
+        SELECT `+revisionColumns+`
+        WHERE status = $1
+        ORDER BY cardinality(flags) > 0 DESC, created_at, id
+        LIMIT $2 OFFSET $3`, status, limit, offset)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	revisions := []domain.ProfileRevision{}
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return 0, nil, err
+		}
+		revisions = append(revisions, *rev)
+	}
+	return total, revisions, rows.Err()
+}
+
+// ApproveRevision vuelca la revisión en la ficha. De profile_data solo se mezclan
+// las claves moderadas, así no se pierden los horarios guardados desde el envío.
+func (r *PostgresRevisionRepository) ApproveRevision(ctx context.Context, id, reviewerID uuid.UUID, slugBase string) error {
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	rev, err := scanRevision(tx.QueryRow(ctx, `SELECT `+revisionColumns+` WHERE id = $1 FOR UPDATE`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrRevisionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if rev.Status != domain.RevisionStatusPending {
+		return domain.ErrRevisionNotPending
+	}
+
+	publicJSON, err := json.Marshal(domain.ModeratedProfileData(rev.ProfileData))
+	if err != nil {
+		return fmt.Errorf("error serializing profile data: %v", err)
+	}
+
+	// Solo se publica la ficha nueva del profesional (PENDING) con su primera aprobación.
+	// Las demás (importadas, reclamadas, ya aprobadas) no cambian de visibilidad: si un
+	// admin la ha ocultado, aprobar un cambio no la vuelve a publicar. Los SET leen la
+	// fila anterior, así is_active ve el status de antes del CASE.
+	_, err = tx.Exec(ctx, `
+        UPDATE professional_entities
+        SET name = $2,
+            entity_type = $3,
+            slug = unique_slug($4, id),
+            profile_data = COALESCE(profile_data, '{}'::jsonb) || $5::jsonb,
+            status = CASE WHEN status = 'PENDING' THEN 'VERIFIED' ELSE status END,
+            is_active = is_active OR status = 'PENDING',
+            updated_at = NOW()
+        WHERE id = $1`,
+		rev.EntityID, rev.Name, rev.EntityType, slugBase, publicJSON)
+	if err != nil {
+		return fmt.Errorf("error publicando revisión: %v", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE profile_revisions
+        SET status = 'APPROVED', reviewed_by = $2, reviewed_at = NOW()
+        WHERE id = $1`, id, reviewerID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRevisionRepository) RejectRevision(ctx context.Context, id, reviewerID uuid.UUID, reason string) error {
+	tag, err := r.Conn.Exec(ctx, `
+        UPDATE profile_revisions
+        SET status = 'REJECTED', reason = $3, reviewed_by = $2, reviewed_at = NOW()
+        WHERE id = $1 AND status = 'PENDING'`, id, reviewerID, reason)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.GetRevision(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrRevisionNotPending
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+	"veterimap-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresSessionRepository struct {
+	Conn *pgxpool.Pool
+}
+
+func NewPostgresSessionRepository(db *pgxpool.Pool) *PostgresSessionRepository {
+	return &PostgresSessionRepository{Conn: db}
+}
+
+func (r *PostgresSessionRepository) CreateSession(ctx context.Context, s *domain.Session, tokenHash string) error {
+	return pgx.BeginFunc(ctx, r.Conn, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+            INSERT INTO sessions (id, user_id, user_agent, ip, expires_at)
+            VALUES ($1, $2, $3, $4, $5)
+            RETURNING created_at, last_seen_at`,
+			s.ID, s.UserID, s.UserAgent, s.IP, s.ExpiresAt,
+		).Scan(&s.CreatedAt, &s.LastSeenAt)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO refresh_tokens (token_hash, session_id) VALUES ($1, $2)`, tokenHash, s.ID)
+		return err
+	})
+}
+
+// RotateRefreshToken bloquea el token y su sesión para que dos refrescos
+// simultáneos con el mismo token no puedan obtener ambos un par nuevo
+func (r *PostgresSessionRepository) RotateRefreshToken(ctx context.Context, oldHash, newHash string, meta domain.SessionMeta, expiresAt time.Time, reuseGrace time.Duration) (*domain.Session, error) {
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var s domain.Session
+	var usedAt, revokedAt *time.Time
+	var expired, justUsed bool
+	var lastUserAgent, lastIP string
+	err = tx.QueryRow(ctx, `
+        SELECT s.id, s.user_id, u.role, t.used_at, s.revoked_at, s.expires_at <= NOW(),
+               COALESCE(t.used_at > NOW() - make_interval(secs => $2), false),
+               s.user_agent, s.ip
+        FROM refresh_tokens t
+        JOIN sessions s ON s.id = t.session_id
+        JOIN users u ON u.id = s.user_id
+        WHERE t.token_hash = $1
+        FOR UPDATE OF t, s`, oldHash, reuseGrace.Seconds(),
+	).Scan(&s.ID, &s.UserID, &s.Role, &usedAt, &revokedAt, &expired, &justUsed, &lastUserAgent, &lastIP)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if usedAt != nil && justUsed && meta.UserAgent == lastUserAgent && meta.IP == lastIP {
+		// Dos pestañas del mismo navegador refrescando a la vez con el mismo token: la
+		// segunda pierde, pero no es una filtración y la sesión sigue viva con el par que
+		// obtuvo la primera. Desde otro dispositivo (user agent o IP distintos a los del
+		// último refresco) cuenta como reutilización aunque esté dentro del margen.
+		return nil, domain.ErrRefreshTokenInvalid
+	}
+	if usedAt != nil {
+		// Reutilización: el token se ha filtrado. La revocación se confirma aunque el refresco falle.
+		_, err := tx.Exec(ctx, `
+            UPDATE sessions SET revoked_at = NOW(), revoked_reason = $2
+            WHERE id = $1 AND revoked_at IS NULL`, s.ID, domain.SessionRevokedReuse)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+		return &s, domain.ErrRefreshTokenReused
+	}
+	if revokedAt != nil || expired {
+		return nil, domain.ErrRefreshTokenInvalid
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE refresh_tokens SET used_at = NOW() WHERE token_hash = $1`, oldHash); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO refresh_tokens (token_hash, session_id) VALUES ($1, $2)`, newHash, s.ID); err != nil {
+		return nil, err
+	}
+	err = tx.QueryRow(ctx, `
+        UPDATE sessions SET last_seen_at = NOW(), expires_at = $2, user_agent = $3, ip = $4
+        WHERE id = $1
+        RETURNING user_agent, ip, created_at, last_seen_at, expires_at`,
+		s.ID, expiresAt, meta.UserAgent, meta.IP,
+	).Scan(&s.UserAgent, &s.IP, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, tx.Commit(ctx)
+}
+
+// RevokeSessionByToken cierra la sesión del token (logout). Un token desconocido no es error:
+// el cliente queda igualmente desconectado.
+func (r *PostgresSessionRepository) RevokeSessionByToken(ctx context.Context, tokenHash, reason string) error {
+	_, err := r.Conn.Exec(ctx, `
+        UPDATE sessions SET revoked_at = NOW(), revoked_reason = $2
+        WHERE id = (SELECT session_id FROM refresh_tokens WHERE token_hash = $1) AND revoked_at IS NULL`,
+		tokenHash, reason)
+	return err
+}
+
+func (r *PostgresSessionRepository) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID, reason string) error {
+	tag, err := r.Conn.Exec(ctx, `
+        UPDATE sessions SET revoked_at = NOW(), revoked_reason = $3
+        WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()`,
+		sessionID, userID, reason)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r *PostgresSessionRepository) RevokeAllSessions(ctx context.Context, userID, except uuid.UUID, reason string) (int, error) {
+	tag, err := r.Conn.Exec(ctx, `
+        UPDATE sessions SET revoked_at = NOW(), revoked_reason = $3
+        WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL AND expires_at > NOW()`, userID, except, reason)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// ListSessions devuelve las sesiones vivas, la más reciente primero
+func (r *PostgresSessionRepository) ListSessions(ctx context.Context, userID uuid.UUID) ([]domain.Session, error) {
+	rows, err := r.Conn.Query(ctx, `
+        SELECT id, user_id, user_agent, ip, created_at, last_seen_at, expires_at
+        FROM sessions
+        WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+        ORDER BY last_seen_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []domain.Session{}
+	for rows.Next() {
+		var s domain.Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
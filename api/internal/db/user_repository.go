@@ -3,11 +3,13 @@ package db
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 	"veterimap-api/internal/domain"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -35,11 +37,12 @@ func (r *PostgresUserRepository) CreateUser(ctx context.Context, u *domain.User)
 			is_verified, 
 			verification_code, 
 			subscription_status,
-			trial_ends_at
+			trial_ends_at,
+			locale
 		) 
 		VALUES (
 			COALESCE(NULLIF($1, '00000000-0000-0000-0000-000000000000'::uuid), gen_random_uuid()), 
-			$2, $3, $4, $5, $6, $7, $8, $9
+			$2, $3, $4, $5, $6, $7, $8, $9, $10
 		) 
 		RETURNING id, created_at`
 
@@ -54,6 +57,7 @@ func (r *PostgresUserRepository) CreateUser(ctx context.Context, u *domain.User)
 		u.VerificationCode,
 		u.SubscriptionStatus, // Ahora dinámico ($8)
 		u.TrialEndsAt,        // Nueva columna ($9)
+		u.Locale,             // Idioma de los correos ($10)
 	).Scan(&u.ID, &u.CreatedAt)
 
 	if err != nil {
@@ -66,11 +70,11 @@ func (r *PostgresUserRepository) CreateUser(ctx context.Context, u *domain.User)
 }
 
 func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
-    // Añadimos trial_ends_at a la consulta (ahora son 13 columnas)
+    // Añadimos trial_ends_at y locale a la consulta (ahora son 14 columnas)
     query := `
         SELECT 
-            id, email, password, role, is_verified, verification_code, 
-            subscription_status, trial_ends_at, name, phone, city, address, postal_code 
+            id, email, password, role, is_verified, COALESCE(verification_code, ''), 
+            subscription_status, trial_ends_at, name, phone, city, address, postal_code, locale 
         FROM users 
         WHERE LOWER(TRIM(email)) = LOWER(TRIM($1))`
 
@@ -89,6 +93,7 @@ func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (
         &u.City,               // 11
         &u.Address,            // 12
         &u.PostalCode,         // 13
+        &u.Locale,             // 14
     )
 
 	if err != nil {
@@ -97,24 +102,9 @@ func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (
 	return &u, nil
 }
 
-func (r *PostgresUserRepository) VerifyUser(ctx context.Context, email string) error {
-	// Importante: No pongas el código a NULL todavía, solo cambia is_verified
-	query := `UPDATE users SET is_verified = true WHERE email = $1`
-	result, err := r.Conn.Exec(ctx, query, email)
-	if err != nil {
-		return err
-	}
-
-	// Si no se actualizó ninguna fila, algo va mal con el email
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("no se encontró el usuario para verificar")
-	}
-	return nil
-}
-
 func (r *PostgresUserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
     // Añadimos trial_ends_at a la consulta
-    query := `SELECT id, email, role, is_verified, subscription_status, trial_ends_at, name, phone, city, address, postal_code 
+    query := `SELECT id, email, role, is_verified, subscription_status, trial_ends_at, name, phone, city, address, postal_code, locale 
               FROM users WHERE id = $1`
 
     var u domain.User
@@ -130,6 +120,7 @@ func (r *PostgresUserRepository) GetUserByID(ctx context.Context, id uuid.UUID)
         &u.City, 
         &u.Address, 
         &u.PostalCode,
+        &u.Locale,
     )
 	if err != nil {
 		return nil, err
@@ -332,13 +323,14 @@ func (r *PostgresUserRepository) UpsertProfessionalProfile(ctx context.Context,
 		INSERT INTO professional_entities (
 			user_id, entity_type, status, name, slug, profile_data, is_active, updated_at
 		) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		VALUES ($1, $2, $3, $4, unique_slug($5, (SELECT id FROM professional_entities WHERE user_id = $1)), $6, $7, NOW())
 		ON CONFLICT (user_id) DO UPDATE SET
 			entity_type = EXCLUDED.entity_type,
 			name = EXCLUDED.name,
+			slug = COALESCE(EXCLUDED.slug, professional_entities.slug),
 			profile_data = EXCLUDED.profile_data,
 			updated_at = NOW()
-		RETURNING id, created_at`
+		RETURNING id, slug, created_at`
 
 	profileDataJSON, err := json.Marshal(p.ProfileData)
 	if err != nil {
@@ -353,7 +345,7 @@ func (r *PostgresUserRepository) UpsertProfessionalProfile(ctx context.Context,
 		p.Slug,
 		profileDataJSON,
 		p.IsActive,
-	).Scan(&p.ID, &p.CreatedAt)
+	).Scan(&p.ID, &p.Slug, &p.CreatedAt)
 
 	if err != nil {
 		return err
@@ -382,6 +374,9 @@ func (r *PostgresUserRepository) GetProfessionalProfileByUserID(ctx context.Cont
 		&p.CreatedAt,   // timestamp
 		&p.UpdatedAt,   // timestamp
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrProfileNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -454,6 +449,31 @@ func (r *PostgresUserRepository) RescheduleAppointment(ctx context.Context, appI
 	return err
 }
 
+// GetAppointmentNotice devuelve la cita con el email del dueño, para avisarle de los cambios
+func (r *PostgresUserRepository) GetAppointmentNotice(ctx context.Context, appID uuid.UUID) (*domain.AppointmentNotice, error) {
+	query := `
+        SELECT 
+            a.id, a.professional_id, a.owner_id, a.pet_id,
+            a.appointment_date, a.status, a.notes, a.created_at,
+            p.name, COALESCE(u.name, ''), pe.name, u.email, u.locale
+        FROM appointments a
+        INNER JOIN pets p ON a.pet_id = p.id
+        INNER JOIN users u ON a.owner_id = u.id
+        INNER JOIN professional_entities pe ON a.professional_id = pe.id
+        WHERE a.id = $1`
+
+	var n domain.AppointmentNotice
+	err := r.Conn.QueryRow(ctx, query, appID).Scan(
+		&n.ID, &n.ProfessionalID, &n.OwnerID, &n.PetID,
+		&n.AppointmentDate, &n.Status, &n.Notes, &n.CreatedAt,
+		&n.PetName, &n.OwnerName, &n.ProfessionalName, &n.OwnerEmail, &n.OwnerLocale,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
 func (r *PostgresUserRepository) GetClientsByProfessionalID(ctx context.Context, profID uuid.UUID) ([]domain.User, error) {
 	// Mejoramos la query: El cliente existe si la cita NO está 'CANCELLED' ni es 'PENDING'
 	// Esto incluye CONFIRMED, COMPLETED y RESCHEDULED
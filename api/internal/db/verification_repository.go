@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+	"veterimap-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresVerificationRepository struct {
+	Conn *pgxpool.Pool
+}
+
+func NewPostgresVerificationRepository(db *pgxpool.Pool) *PostgresVerificationRepository {
+	return &PostgresVerificationRepository{Conn: db}
+}
+
+func (r *PostgresVerificationRepository) GetVerification(ctx context.Context, email string) (*domain.Verification, error) {
+	var v domain.Verification
+	err := r.Conn.QueryRow(ctx, `
+        SELECT id, email, name, locale, is_verified, COALESCE(verification_code, ''), verification_expires_at, verification_attempts
+        FROM users
+        WHERE LOWER(TRIM(email)) = LOWER(TRIM($1))`, email,
+	).Scan(&v.UserID, &v.Email, &v.Name, &v.Locale, &v.IsVerified, &v.Code, &v.ExpiresAt, &v.Attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *PostgresVerificationRepository) SetVerificationCode(ctx context.Context, userID uuid.UUID, code string, expiresAt time.Time) error {
+	return pgx.BeginFunc(ctx, r.Conn, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+            UPDATE users SET verification_code = $2, verification_expires_at = $3, verification_attempts = 0
+            WHERE id = $1`, userID, code, expiresAt)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrUserNotFound
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO verification_sends (user_id) VALUES ($1)`, userID)
+		return err
+	})
+}
+
+// ConsumeVerificationAttempt suma el intento en el mismo UPDATE que comprueba el
+// límite, para que una ráfaga de peticiones en paralelo no lo pueda rebasar
+func (r *PostgresVerificationRepository) ConsumeVerificationAttempt(ctx context.Context, userID uuid.UUID, maxAttempts int) (*domain.Verification, error) {
+	v := domain.Verification{UserID: userID}
+	err := r.Conn.QueryRow(ctx, `
+        UPDATE users SET verification_attempts = verification_attempts + 1
+        WHERE id = $1 AND NOT is_verified AND verification_attempts < $2
+        RETURNING email, name, COALESCE(verification_code, ''), verification_expires_at, verification_attempts`,
+		userID, maxAttempts,
+	).Scan(&v.Email, &v.Name, &v.Code, &v.ExpiresAt, &v.Attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrVerificationLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *PostgresVerificationRepository) CompleteVerification(ctx context.Context, userID uuid.UUID) error {
+	tag, err := r.Conn.Exec(ctx, `
+        UPDATE users
+        SET is_verified = true, verification_code = NULL, verification_expires_at = NULL, verification_attempts = 0
+        WHERE id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *PostgresVerificationRepository) RecentVerificationSends(ctx context.Context, userID uuid.UUID, since time.Time) ([]time.Time, error) {
+	rows, err := r.Conn.Query(ctx, `
+        SELECT sent_at FROM verification_sends
+        WHERE user_id = $1 AND sent_at >= $2
+        ORDER BY sent_at DESC`, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sends := []time.Time{}
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		sends = append(sends, t)
+	}
+	return sends, rows.Err()
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUserNotFound      = errors.New("usuario no encontrado")
+	ErrAdminInvalid      = errors.New("datos inválidos")
+	ErrAlreadyVerified   = errors.New("la cuenta ya está verificada")
+	ErrEntityStatusOwner = errors.New("el estado no encaja con el titular de la ficha")
+)
+
+// SubscriptionStatuses son los valores de subscription_status que entiende la API
+var SubscriptionStatuses = []string{"free", "essential", "premium", "trialing", "active"}
+
+// AdminUser es la vista de una cuenta en el panel de administración
+type AdminUser struct {
+	ID                 uuid.UUID   `json:"id"`
+	Email              string      `json:"email"`
+	Name               *string     `json:"name"`
+	Role               Role        `json:"role"`
+	IsVerified         bool        `json:"is_verified"`
+	SubscriptionStatus string      `json:"subscription_status"`
+	TrialEndsAt        *time.Time  `json:"trial_ends_at"`
+	Locale             string      `json:"locale"`
+	CreatedAt          time.Time   `json:"created_at"`
+	Entity             *EntityInfo `json:"entity,omitempty"` // Ficha profesional de la que es titular
+}
+
+// EntityInfo resume una ficha profesional para los listados de administración
+type EntityInfo struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     *uuid.UUID `json:"user_id,omitempty"`
+	Name       string     `json:"name"`
+	Slug       *string    `json:"slug,omitempty"`
+	EntityType string     `json:"entity_type"`
+	Status     string     `json:"status"`
+	IsActive   bool       `json:"is_active"`
+	City       string     `json:"city,omitempty"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type UserFilter struct {
+	Query              string // Email o nombre, sin distinguir mayúsculas
+	Role               Role
+	Verified           *bool
+	SubscriptionStatus string
+	Limit              int
+	Offset             int
+}
+
+type EntityFilter struct {
+	Query  string // Nombre o slug
+	Status string
+	Active *bool
+	Limit  int
+	Offset int
+}
+
+// SubscriptionChange es un cambio parcial: los campos a nil no se tocan
+type SubscriptionChange struct {
+	Status      *string
+	TrialEndsAt **time.Time // Puntero a nil para quitar el periodo de prueba
+}
+
+type AdminRepository interface {
+	SearchUsers(ctx context.Context, f UserFilter) (int, []AdminUser, error)
+	GetAdminUser(ctx context.Context, id uuid.UUID) (*AdminUser, error)
+	UpdateSubscription(ctx context.Context, id uuid.UUID, status string, trialEndsAt *time.Time) error
+
+	SearchEntities(ctx context.Context, f EntityFilter) (int, []EntityInfo, error)
+	GetEntity(ctx context.Context, id uuid.UUID) (*EntityInfo, error)
+	SetEntityActive(ctx context.Context, id uuid.UUID, active bool) error
+	SetEntityStatus(ctx context.Context, id uuid.UUID, status string) error
+}
+
+type AdminService interface {
+	SearchUsers(ctx context.Context, f UserFilter) (int, []AdminUser, error)
+	GetUser(ctx context.Context, id uuid.UUID) (*AdminUser, error)
+	UpdateSubscription(ctx context.Context, adminID, id uuid.UUID, change SubscriptionChange) (*AdminUser, error)
+	ResendVerification(ctx context.Context, adminID, id uuid.UUID) error
+
+	SearchEntities(ctx context.Context, f EntityFilter) (int, []EntityInfo, error)
+	SetEntityActive(ctx context.Context, adminID, id uuid.UUID, active bool) (*EntityInfo, error)
+	SetEntityStatus(ctx context.Context, adminID, id uuid.UUID, status string) (*EntityInfo, error)
+}
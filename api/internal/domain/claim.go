@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrClaimNotFound        = errors.New("reclamación no encontrada")
+	ErrClaimNotAllowed      = errors.New("esta ficha ya tiene titular y no se puede reclamar")
+	ErrClaimNoContact       = errors.New("la ficha no tiene un contacto para ese método de verificación")
+	ErrClaimNotPending      = errors.New("la reclamación ya no está pendiente")
+	ErrClaimCodeInvalid     = errors.New("código de verificación incorrecto")
+	ErrClaimCodeExpired     = errors.New("el código de verificación ha caducado")
+	ErrClaimTooManyAttempts = errors.New("demasiados intentos: solicita un nuevo código")
+	ErrClaimThrottled       = errors.New("demasiadas reclamaciones: espera antes de pedir otra")
+)
+
+// ClaimThrottledError indica cuánto falta para poder abrir otra reclamación
+type ClaimThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ClaimThrottledError) Error() string {
+	return fmt.Sprintf("%v (%s)", ErrClaimThrottled, e.RetryAfter.Round(time.Second))
+}
+
+func (e *ClaimThrottledError) Is(target error) bool {
+	return target == ErrClaimThrottled
+}
+
+// ClaimMethod es cómo demuestra el profesional que la ficha es suya
+type ClaimMethod string
+
+const (
+	ClaimMethodEmail ClaimMethod = "EMAIL" // Código al ContactInfo.Email de la ficha
+	ClaimMethodPhone ClaimMethod = "PHONE" // Revisión manual: el admin llama al ContactInfo.Phone (sin SMS todavía)
+	ClaimMethodAdmin ClaimMethod = "ADMIN" // Revisión manual de las pruebas aportadas
+)
+
+type ClaimStatus string
+
+const (
+	ClaimStatusPending   ClaimStatus = "PENDING"
+	ClaimStatusApproved  ClaimStatus = "APPROVED"
+	ClaimStatusRejected  ClaimStatus = "REJECTED"
+	ClaimStatusCancelled ClaimStatus = "CANCELLED"
+)
+
+// ProfileClaim es la solicitud de un profesional para hacerse con una ficha PROSPECT
+type ProfileClaim struct {
+	ID          uuid.UUID   `json:"id"`
+	EntityID    uuid.UUID   `json:"entity_id"`
+	EntityName  string      `json:"entity_name,omitempty"`
+	UserID      uuid.UUID   `json:"user_id"`
+	UserEmail   string      `json:"user_email,omitempty"`
+	Method      ClaimMethod `json:"method"`
+	Status      ClaimStatus `json:"status"`
+	Destination string      `json:"destination,omitempty"` // Enmascarado al devolverlo al profesional
+	CodeHash    string      `json:"-"`
+	Attempts    int         `json:"attempts"`
+	ExpiresAt   *time.Time  `json:"expires_at,omitempty"`
+	Evidence    string      `json:"evidence,omitempty"`
+	ReviewNote  string      `json:"review_note,omitempty"`
+	ReviewedBy  *uuid.UUID  `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time  `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+type ClaimRepository interface {
+	// CreateClaim cancela las reclamaciones abiertas del mismo usuario sobre la ficha y crea la nueva
+	CreateClaim(ctx context.Context, c *ProfileClaim) error
+	// RecentClaims devuelve las reclamaciones del usuario creadas desde since, las más recientes primero
+	RecentClaims(ctx context.Context, userID uuid.UUID, since time.Time) ([]ProfileClaim, error)
+	GetClaim(ctx context.Context, id uuid.UUID) (*ProfileClaim, error)
+	ListClaimsByUser(ctx context.Context, userID uuid.UUID) ([]ProfileClaim, error)
+	ListClaimsByStatus(ctx context.Context, status ClaimStatus) ([]ProfileClaim, error)
+	// ConsumeClaimAttempt gasta un intento de una reclamación pendiente si le quedan
+	// (menos de maxAttempts) y devuelve los gastados; si no, ErrClaimTooManyAttempts.
+	// Comprobar y sumar van en la misma sentencia para que las peticiones simultáneas
+	// no se salten el límite.
+	ConsumeClaimAttempt(ctx context.Context, id uuid.UUID, maxAttempts int) (int, error)
+	RejectClaim(ctx context.Context, id uuid.UUID, reviewer uuid.UUID, note string) error
+	// CompleteClaim asigna la ficha al usuario (status CLAIMED) en una transacción: mueve
+	// las citas de su ficha anterior, la desactiva y rechaza las demás reclamaciones
+	CompleteClaim(ctx context.Context, id uuid.UUID, reviewer *uuid.UUID) error
+}
+
+type ClaimService interface {
+	RequestClaim(ctx context.Context, userID, entityID uuid.UUID, method ClaimMethod, evidence string) (*ProfileClaim, error)
+	VerifyClaim(ctx context.Context, userID, claimID uuid.UUID, code string) (*ProfileClaim, error)
+	ListMyClaims(ctx context.Context, userID uuid.UUID) ([]ProfileClaim, error)
+	ListClaims(ctx context.Context, status ClaimStatus) ([]ProfileClaim, error)
+	ApproveClaim(ctx context.Context, adminID, claimID uuid.UUID) (*ProfileClaim, error)
+	RejectClaim(ctx context.Context, adminID, claimID uuid.UUID, note string) (*ProfileClaim, error)
+}
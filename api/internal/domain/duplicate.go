@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMergeSameEntity = errors.New("no se puede fusionar una ficha consigo misma")
+	ErrMergeBothOwned  = errors.New("las dos fichas tienen titular; hay que resolverlo a mano")
+)
+
+// Pesos de la puntuación de duplicados (suman 1) y umbral por defecto de la cola
+const (
+	DuplicateWeightName     = 0.45
+	DuplicateWeightPhone    = 0.35
+	DuplicateWeightDistance = 0.20
+	DuplicateMaxDistanceKm  = 0.5 // A partir de aquí la cercanía no puntúa
+	DuplicateDefaultScore   = 0.5
+)
+
+// DuplicateCandidate es una pareja de fichas que probablemente son la misma
+type DuplicateCandidate struct {
+	A              EntityInfo `json:"a"`
+	B              EntityInfo `json:"b"`
+	Score          float64    `json:"score"`           // 0..1
+	NameSimilarity float64    `json:"name_similarity"` // Trigramas sobre el nombre normalizado
+	SamePhone      bool       `json:"same_phone"`
+	DistanceKm     *float64   `json:"distance_km,omitempty"` // Entre sus direcciones más cercanas
+}
+
+// EntityMerge es el resultado de fusionar merged en survivor
+type EntityMerge struct {
+	ID         uuid.UUID `json:"id"`
+	SurvivorID uuid.UUID `json:"survivor_id"`
+	MergedID   uuid.UUID `json:"merged_id"`
+	MergedSlug *string   `json:"merged_slug,omitempty"` // Ahora redirige a la superviviente
+	MergedBy   uuid.UUID `json:"merged_by"`
+}
+
+type DuplicateRepository interface {
+	FindDuplicates(ctx context.Context, minScore float64, limit, offset int) (int, []DuplicateCandidate, error)
+	DismissDuplicate(ctx context.Context, a, b, adminID uuid.UUID) error
+	// MergeEntities traslada citas, historiales, reseñas, reclamaciones y slugs de
+	// merged a survivor, completa su profile_data con MergeProfileData y borra merged
+	MergeEntities(ctx context.Context, m *EntityMerge) error
+}
+
+type DuplicateService interface {
+	ListDuplicates(ctx context.Context, minScore float64, limit, offset int) (int, []DuplicateCandidate, error)
+	Dismiss(ctx context.Context, adminID, a, b uuid.UUID) error
+	Merge(ctx context.Context, adminID, survivorID, mergedID uuid.UUID) (*EntityMerge, error)
+}
+
+// MergeProfileData completa los datos de la superviviente con los de la absorbida:
+// lo que ya tiene la superviviente manda, lo vacío se rellena y las listas se unen
+// sin repetir. Las direcciones añadidas nunca pasan a ser la principal.
+func MergeProfileData(survivor, merged ProfileData) ProfileData {
+	out := survivor
+
+	out.LicenseNumber = firstNonEmpty(survivor.LicenseNumber, merged.LicenseNumber)
+	out.Bio = firstNonEmpty(survivor.Bio, merged.Bio)
+	out.LogoURL = firstNonEmpty(survivor.LogoURL, merged.LogoURL)
+	out.ScheduleText = firstNonEmpty(survivor.ScheduleText, merged.ScheduleText)
+	out.Contact.Phone = firstNonEmpty(survivor.Contact.Phone, merged.Contact.Phone)
+	out.Contact.Email = firstNonEmpty(survivor.Contact.Email, merged.Contact.Email)
+
+	out.Addresses = append([]AddressData{}, survivor.Addresses...)
+	for _, a := range merged.Addresses {
+		if !containsAddress(out.Addresses, a.FullAddress) {
+			a.IsMain = false
+			out.Addresses = append(out.Addresses, a)
+		}
+	}
+
+	out.Specialties = unionStrings(survivor.Specialties, merged.Specialties)
+	out.Specialization.Experience = firstNonEmpty(survivor.Specialization.Experience, merged.Specialization.Experience)
+	out.Specialization.Specialties = unionStrings(survivor.Specialization.Specialties, merged.Specialization.Specialties)
+	out.Specialization.DetailedServices = unionServices(survivor.Specialization.DetailedServices, merged.Specialization.DetailedServices)
+	out.Pricing.Tarifas = unionServices(survivor.Pricing.Tarifas, merged.Pricing.Tarifas)
+
+	if len(survivor.WorkingHours) == 0 {
+		out.WorkingHours = merged.WorkingHours
+	}
+	out.Holidays = append(append([]Holiday{}, survivor.Holidays...), merged.Holidays...)
+	if !survivor.Insurance.Accepts {
+		out.Insurance = merged.Insurance
+	}
+	return out
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func sameText(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+func containsAddress(list []AddressData, fullAddress string) bool {
+	for _, a := range list {
+		if sameText(a.FullAddress, fullAddress) {
+			return true
+		}
+	}
+	return false
+}
+
+func unionStrings(a, b []string) []string {
+	out := append([]string{}, a...)
+next:
+	for _, s := range b {
+		for _, existing := range out {
+			if sameText(existing, s) {
+				continue next
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func unionServices(a, b []ServiceData) []ServiceData {
+	out := append([]ServiceData{}, a...)
+next:
+	for _, s := range b {
+		for _, existing := range out {
+			if sameText(existing.Name, s.Name) {
+				continue next
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
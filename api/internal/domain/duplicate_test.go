@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeProfileData(t *testing.T) {
+	weekday := map[string]WorkingDay{"monday": {Active: true, Start: "09:00", End: "14:00"}}
+	saturday := map[string]WorkingDay{"saturday": {Active: true, Start: "10:00", End: "13:00"}}
+
+	tests := []struct {
+		name     string
+		survivor ProfileData
+		merged   ProfileData
+		want     ProfileData
+	}{
+		{
+			name:     "lo de la superviviente manda y lo vacío se rellena",
+			survivor: ProfileData{Bio: "Clínica de barrio", Contact: ContactInfo{Phone: "954 12 34 56"}},
+			merged:   ProfileData{Bio: "Otra bio", LicenseNumber: "SE-1234", Contact: ContactInfo{Phone: "600 11 22 33", Email: "info@sol.es"}},
+			want: ProfileData{
+				Bio: "Clínica de barrio", LicenseNumber: "SE-1234",
+				Contact:   ContactInfo{Phone: "954 12 34 56", Email: "info@sol.es"},
+				Addresses: []AddressData{}, Specialties: []string{},
+				Specialization: SpecializationData{Specialties: []string{}, DetailedServices: []ServiceData{}},
+				Pricing:        PricingModel{Tarifas: []ServiceData{}},
+				Holidays:       []Holiday{},
+			},
+		},
+		{
+			name: "listas unidas sin repetir y direcciones añadidas como secundarias",
+			survivor: ProfileData{
+				Addresses:   []AddressData{{FullAddress: "Calle Sol 1", IsMain: true}},
+				Specialties: []string{"Exóticos"},
+				Specialization: SpecializationData{
+					DetailedServices: []ServiceData{{Name: "Vacunación"}},
+				},
+			},
+			merged: ProfileData{
+				Addresses:   []AddressData{{FullAddress: " calle sol 1 ", IsMain: true}, {FullAddress: "Avenida Mar 7", IsMain: true}},
+				Specialties: []string{"exóticos", "Cirugía"},
+				Specialization: SpecializationData{
+					Specialties:      []string{"Dermatología"},
+					DetailedServices: []ServiceData{{Name: "vacunación"}, {Name: "Ecografía"}},
+				},
+			},
+			want: ProfileData{
+				Addresses:   []AddressData{{FullAddress: "Calle Sol 1", IsMain: true}, {FullAddress: "Avenida Mar 7"}},
+				Specialties: []string{"Exóticos", "Cirugía"},
+				Specialization: SpecializationData{
+					Specialties:      []string{"Dermatología"},
+					DetailedServices: []ServiceData{{Name: "Vacunación"}, {Name: "Ecografía"}},
+				},
+				Pricing:  PricingModel{Tarifas: []ServiceData{}},
+				Holidays: []Holiday{},
+			},
+		},
+		{
+			name:     "horario y seguros solo si la superviviente no los tiene",
+			survivor: ProfileData{WorkingHours: weekday},
+			merged:   ProfileData{WorkingHours: saturday, Insurance: InsuranceData{Accepts: true, Companies: "Mapfre"}},
+			want: ProfileData{
+				WorkingHours: weekday,
+				Insurance:    InsuranceData{Accepts: true, Companies: "Mapfre"},
+				Addresses:    []AddressData{}, Specialties: []string{},
+				Specialization: SpecializationData{Specialties: []string{}, DetailedServices: []ServiceData{}},
+				Pricing:        PricingModel{Tarifas: []ServiceData{}},
+				Holidays:       []Holiday{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeProfileData(tt.survivor, tt.merged)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeProfileData =\n%+v\nse esperaba\n%+v", got, tt.want)
+			}
+		})
+	}
+}
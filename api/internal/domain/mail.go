@@ -0,0 +1,19 @@
+package domain
+
+import "context"
+
+// Plantillas de correo (internal/pkg/mailer/templates/<idioma>/<nombre>.{txt,html})
+const (
+	EmailVerification           = "verification"            // Name, Code, TTLMinutes
+	EmailPasswordReset          = "password_reset"          // URL, TTLMinutes
+	EmailClaimCode              = "claim_code"              // EntityName, Code, TTLMinutes
+	EmailAppointmentConfirmed   = "appointment_confirmed"   // OwnerName, PetName, ProfessionalName, Date
+	EmailAppointmentRescheduled = "appointment_rescheduled" // Ídem + Notes
+	EmailTrialEnding            = "trial_ending"            // Name, TrialEndsAt
+)
+
+// Mailer envía un correo de plantilla. La entrega es asíncrona y con reintentos:
+// un error aquí es de la plantilla o de la cola, no del servidor de correo.
+type Mailer interface {
+	Send(ctx context.Context, to, locale, template string, data map[string]interface{}) error
+}
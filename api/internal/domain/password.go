@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrResetTokenInvalid = errors.New("el enlace de recuperación no es válido o ha caducado")
+	ErrWrongPassword     = errors.New("la contraseña actual no es correcta")
+	ErrWeakPassword      = errors.New("la contraseña no cumple los requisitos")
+)
+
+type PasswordRepository interface {
+	GetPasswordHash(ctx context.Context, userID uuid.UUID) (string, error)
+	UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
+	// PasswordChangedAt es la fecha del último cambio o recuperación (nil si nunca)
+	PasswordChangedAt(ctx context.Context, userID uuid.UUID) (*time.Time, error)
+	CreatePasswordReset(ctx context.Context, userID uuid.UUID, tokenHash, ip string, expiresAt time.Time) error
+	// CountRecentResets cuenta las solicitudes de un usuario desde since (límite de envíos)
+	CountRecentResets(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+	// ConsumePasswordReset marca el token como usado, invalida el resto de enlaces
+	// del usuario y guarda la nueva contraseña, todo en una transacción
+	ConsumePasswordReset(ctx context.Context, tokenHash, passwordHash string) (uuid.UUID, error)
+}
+
+type PasswordService interface {
+	// RequestReset no revela si el email existe: siempre termina sin error para el cliente
+	RequestReset(ctx context.Context, email string, meta SessionMeta) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	// ChangePassword mantiene la sesión actual y cierra las demás
+	ChangePassword(ctx context.Context, userID, currentSessionID uuid.UUID, currentPassword, newPassword string) error
+}
@@ -2,11 +2,20 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Estados de una ficha profesional
+const (
+	EntityStatusProspect = "PROSPECT" // Importada, sin titular
+	EntityStatusPending  = "PENDING"  // Creada por el profesional, sin la primera aprobación
+	EntityStatusClaimed  = "CLAIMED"  // Reclamada por su titular
+	EntityStatusVerified = "VERIFIED" // Creada por el profesional y aprobada por un admin
+)
+
 type ProfessionalEntity struct {
 	ID          uuid.UUID   `json:"id"`
 	UserID      *uuid.UUID  `json:"user_id"`
@@ -20,6 +29,9 @@ type ProfessionalEntity struct {
 	IsActive    bool        `json:"is_active"`
 	CreatedAt   time.Time   `json:"created_at"`
 	UpdatedAt   time.Time   `json:"updated_at"`
+
+	// Último envío del titular (pendiente, aprobado o rechazado con motivo); solo en el modo "Editar"
+	Revision *ProfileRevision `json:"revision,omitempty"`
 }
 
 type ProfileData struct {
@@ -30,11 +42,26 @@ type ProfileData struct {
 	Contact        ContactInfo           `json:"contact"`
 	Specialization SpecializationData    `json:"specialization,omitempty"`
 	Specialties    []string              `json:"specialties"`
-	WorkingHours   map[string]WorkingDay `json:"working_hours"`
+	WorkingHours   map[string]WorkingDay `json:"working_hours"` // Claves: WeekdayKeys (monday...sunday)
+	Holidays       []Holiday             `json:"holidays,omitempty"`
+	ScheduleText   string                `json:"schedule_text,omitempty"` // Horario original en texto libre (importaciones)
 	Pricing        PricingModel          `json:"pricing"`
 	Insurance      InsuranceData         `json:"insurance_partners"`
 }
 
+// MainCity devuelve la ciudad de la dirección principal (o de la primera)
+func (p ProfileData) MainCity() string {
+	for _, a := range p.Addresses {
+		if a.IsMain {
+			return a.City
+		}
+	}
+	if len(p.Addresses) > 0 {
+		return p.Addresses[0].City
+	}
+	return ""
+}
+
 type AddressData struct {
 	FullAddress string  `json:"full_address"`
 	City        string  `json:"city"`
@@ -55,10 +82,13 @@ type SpecializationData struct {
 	DetailedServices []ServiceData `json:"detailed_services"`
 }
 
+// WorkingDay es el horario de un día. Start/End se mantienen por compatibilidad con
+// los formularios actuales; Shifts permite turnos partidos (mañana y tarde).
 type WorkingDay struct {
-	Active bool   `json:"active"`
-	Start  string `json:"start"`
-	End    string `json:"end"`
+	Active bool        `json:"active"`
+	Start  string      `json:"start"`
+	End    string      `json:"end"`
+	Shifts []TimeRange `json:"shifts,omitempty"`
 }
 
 type PricingModel struct {
@@ -86,6 +116,111 @@ type ProfileSummary struct {
 	FullAddress string  `json:"full_address"`
 	Latitude    float64 `json:"lat"`
 	Longitude   float64 `json:"lng"`
+	// Ubicación concreta (posición en profile_data.addresses) que ha casado con la búsqueda
+	AddressIndex int      `json:"address_index"`
+	IsMain       bool     `json:"is_main"`
+	DistanceKm   *float64 `json:"distance_km,omitempty"` // Solo cuando la búsqueda tiene un punto de referencia
+	Snippet      string   `json:"snippet,omitempty"`     // Fragmento con <mark> cuando hay búsqueda de texto
+	// Horario: nil si el profesional no tiene un horario estructurado
+	IsOpenNow   *bool      `json:"is_open_now"`
+	NextOpening *time.Time `json:"next_opening,omitempty"`
+}
+
+// GeoPoint es una coordenada WGS84 (la misma que guardamos en AddressData)
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// GeoBounds es el rectángulo visible del mapa: esquina suroeste y noreste
+type GeoBounds struct {
+	SouthWest GeoPoint `json:"sw"`
+	NorthEast GeoPoint `json:"ne"`
+}
+
+// Center devuelve el punto medio del rectángulo, usado para ordenar por distancia
+func (b GeoBounds) Center() GeoPoint {
+	return GeoPoint{
+		Lat: (b.SouthWest.Lat + b.NorthEast.Lat) / 2,
+		Lng: (b.SouthWest.Lng + b.NorthEast.Lng) / 2,
+	}
+}
+
+// ProfileFilter agrupa los criterios de SearchProfiles.
+// Bounds y Center/RadiusKm son opcionales; si hay cualquiera de los dos
+// los resultados se ordenan por distancia y llevan distance_km.
+// PerLocation devuelve una fila por dirección (pines del mapa); si es false,
+// una por profesional con la dirección que mejor casa (la principal si empatan).
+type ProfileFilter struct {
+	Query       string // Texto libre: nombre, especialidades, servicios, ciudad y bio
+	City        string
+	Tag         string
+	Bounds      *GeoBounds
+	Center      *GeoPoint
+	RadiusKm    float64
+	PerLocation bool
+	OpenAt      *time.Time // Solo profesionales abiertos en ese instante (hora de Madrid)
+	// Filtros del panel lateral del marketplace (cada uno tiene su faceta)
+	EntityType       string
+	Specialty        string
+	MinRating        float64
+	AcceptsInsurance bool
+	Sort             string // Uno de los ProfileSort*; vacío = relevancia, distancia o valoración según el filtro
+	Cursor           string // Cursor opaco de ProfilePage.NextCursor; si viene se ignora Offset
+	Limit            int
+	Offset           int
+}
+
+// Ordenaciones admitidas en los listados (?sort=)
+const (
+	ProfileSortRelevance = "relevance" // Requiere búsqueda de texto
+	ProfileSortDistance  = "distance"  // Requiere punto de referencia (lat/lng o bbox)
+	ProfileSortRating    = "rating"
+	ProfileSortReviews   = "reviews"
+	ProfileSortName      = "name"
+	ProfileSortNewest    = "newest"
+)
+
+// ErrProfileNotFound indica que no hay perfil con ese id o slug
+var ErrProfileNotFound = errors.New("perfil no encontrado")
+
+// ErrProfileNameRequired: una ficha sin nombre no tiene slug ni se puede publicar
+var ErrProfileNameRequired = errors.New("el nombre profesional es obligatorio")
+
+// ErrInvalidCursor indica un cursor corrupto o de otra ordenación
+var ErrInvalidCursor = errors.New("cursor de paginación inválido")
+
+// ProfilePage es una página de resultados. NextCursor va vacío en la última página.
+type ProfilePage struct {
+	Total      int
+	Profiles   []ProfileSummary
+	NextCursor string
+}
+
+// ProfileCluster agrupa los pines cercanos cuando el mapa está alejado
+type ProfileCluster struct {
+	Latitude   float64   `json:"lat"` // Centroide de los pines agrupados
+	Longitude  float64   `json:"lng"`
+	Count      int       `json:"count"`
+	EntityType string    `json:"entity_type"` // Tipo dominante dentro del grupo
+	Bounds     GeoBounds `json:"bounds"`      // Para hacer zoom al pulsar el cluster
+}
+
+// Tipos de sugerencia del autocompletado
+const (
+	SuggestionClinic    = "clinic"
+	SuggestionCity      = "city"
+	SuggestionSpecialty = "specialty"
+)
+
+// Suggestion es una entrada del autocompletado del buscador
+type Suggestion struct {
+	Type       string `json:"type"` // clinic | city | specialty
+	Label      string `json:"label"`
+	ID         string `json:"id,omitempty"`          // Solo clinic
+	EntityType string `json:"entity_type,omitempty"` // Solo clinic
+	City       string `json:"city,omitempty"`        // Solo clinic: ciudad principal
+	Count      int    `json:"count,omitempty"`       // city / specialty: nº de profesionales
 }
 
 type ProfileDetail struct {
@@ -95,9 +230,32 @@ type ProfileDetail struct {
 	AccessLevel        int        `json:"access_level"`
 }
 
+// FacetBucket es un valor de filtro con el nº de profesionales que obtendría
+type FacetBucket struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// ProfileFacets son los contadores del panel de filtros. Cada faceta se calcula con
+// el resto de filtros activos pero sin el suyo propio, para que el usuario vea
+// cuántos resultados tendría al cambiar de opción. Se cuentan profesionales, no direcciones.
+type ProfileFacets struct {
+	EntityType       []FacetBucket `json:"entity_type"`
+	Specialty        []FacetBucket `json:"specialty"`
+	City             []FacetBucket `json:"city"`
+	Rating           []FacetBucket `json:"rating"` // Acumulado: "4.5" = 4,5 estrellas o más
+	AcceptsInsurance int           `json:"accepts_insurance"`
+}
+
 type ProfileRepository interface {
-	SearchProfiles(ctx context.Context, name, city, tag string, limit, offset int) (int, []ProfileSummary, error)
+	SearchProfiles(ctx context.Context, f ProfileFilter) (*ProfilePage, error)
+	ClusterProfiles(ctx context.Context, f ProfileFilter, cellSizeDeg float64) ([]ProfileCluster, error)
+	TileProfiles(ctx context.Context, f ProfileFilter) ([]ProfileSummary, error)
+	FacetProfiles(ctx context.Context, f ProfileFilter) (*ProfileFacets, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]Suggestion, error)
 	GetProfileDetail(ctx context.Context, id string) (*ProfileDetail, error)
+	// ResolveSlug devuelve el id y el slug actual de un slug vigente o antiguo de una ficha activa
+	ResolveSlug(ctx context.Context, slug string) (id string, current string, err error)
 	UpsertProfessionalProfile(ctx context.Context, p *ProfessionalEntity) error
 	GetProfessionalProfileByUserID(ctx context.Context, userID uuid.UUID) (*ProfessionalEntity, error)
 }
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrReviewInvalid        = errors.New("datos de reseña inválidos")
+	ErrReviewNotFound       = errors.New("reseña no encontrada")
+	ErrReviewNotAllowed     = errors.New("solo puedes valorar tus citas completadas")
+	ErrReviewDuplicate      = errors.New("ya has valorado esta cita")
+	ErrReviewAlreadyReplied = errors.New("la reseña ya tiene respuesta")
+	ErrReviewAlreadyReport  = errors.New("ya has reportado esta reseña")
+	ErrReviewStatusInvalid  = errors.New("estado de reseña no válido")
+)
+
+// AppointmentStatusCompleted es el estado que habilita la reseña
+const AppointmentStatusCompleted = "COMPLETED"
+
+type ReviewStatus string
+
+const (
+	ReviewStatusPublished ReviewStatus = "PUBLISHED"
+	ReviewStatusHidden    ReviewStatus = "HIDDEN" // Oculta por reportes o moderación; no cuenta en el rating
+)
+
+// Review es la valoración de un dueño sobre una cita completada
+type Review struct {
+	ID            uuid.UUID    `json:"id"`
+	EntityID      uuid.UUID    `json:"entity_id"`
+	AppointmentID uuid.UUID    `json:"appointment_id"`
+	AuthorID      uuid.UUID    `json:"-"`
+	AuthorName    string       `json:"author_name"` // Solo el nombre de pila
+	Stars         int          `json:"stars"`
+	Body          string       `json:"body"`
+	Status        ReviewStatus `json:"status"`
+	Reply         *string      `json:"reply,omitempty"` // Respuesta pública del profesional
+	RepliedAt     *time.Time   `json:"replied_at,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// ReportedReview es la reseña tal como la ve un moderador: con sus reportes y la
+// última decisión tomada (ModeratedAt vacío = oculta por reportes, sin revisar)
+type ReportedReview struct {
+	Review
+	ReportCount   int        `json:"report_count"`
+	ReportReasons []string   `json:"report_reasons"`
+	ModeratedBy   *uuid.UUID `json:"moderated_by,omitempty"`
+	ModeratedAt   *time.Time `json:"moderated_at,omitempty"`
+}
+
+type ReviewRepository interface {
+	// GetAppointment devuelve la cita que se quiere valorar (para comprobar dueño y estado)
+	GetAppointment(ctx context.Context, id uuid.UUID) (*Appointment, error)
+	// CreateReview inserta la reseña y recalcula rating/review_count en la misma transacción
+	CreateReview(ctx context.Context, rv *Review) error
+	GetReview(ctx context.Context, id uuid.UUID) (*Review, error)
+	ListPublishedReviews(ctx context.Context, entityID uuid.UUID, limit, offset int) (int, []Review, error)
+	SetReply(ctx context.Context, reviewID uuid.UUID, reply string) error
+	// ReportReview registra el reporte y oculta la reseña (recalculando el rating) al
+	// llegar a hideThreshold reportes, salvo que un admin ya la haya moderado.
+	// Devuelve si ha quedado oculta.
+	ReportReview(ctx context.Context, reviewID, reporterID uuid.UUID, reason string, hideThreshold int) (bool, error)
+	// ListReviewsByStatus pagina las reseñas de un estado, las no moderadas primero
+	ListReviewsByStatus(ctx context.Context, status ReviewStatus, limit, offset int) (int, []ReportedReview, error)
+	// ModerateReview fija el estado decidido por un admin y recalcula el rating
+	ModerateReview(ctx context.Context, reviewID, adminID uuid.UUID, status ReviewStatus) error
+}
+
+type ReviewService interface {
+	CreateReview(ctx context.Context, authorID, appointmentID uuid.UUID, stars int, body string) (*Review, error)
+	// ListReviews acepta el id o el slug de la ficha
+	ListReviews(ctx context.Context, profileRef string, limit, offset int) (int, []Review, error)
+	Reply(ctx context.Context, professionalUserID, reviewID uuid.UUID, reply string) (*Review, error)
+	Report(ctx context.Context, reporterID, reviewID uuid.UUID, reason string) error
+
+	// Moderación (admin)
+	ListModeration(ctx context.Context, status ReviewStatus, limit, offset int) (int, []ReportedReview, error)
+	// Restore vuelve a publicar una reseña oculta por reportes
+	Restore(ctx context.Context, adminID, reviewID uuid.UUID) (*Review, error)
+	// Hide confirma la ocultación (o oculta una publicada)
+	Hide(ctx context.Context, adminID, reviewID uuid.UUID) (*Review, error)
+}
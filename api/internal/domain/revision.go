@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRevisionNotFound   = errors.New("revisión no encontrada")
+	ErrRevisionNotPending = errors.New("la revisión ya no está pendiente")
+	ErrRevisionNoReason   = errors.New("indica el motivo del rechazo")
+)
+
+type RevisionStatus string
+
+const (
+	RevisionStatusPending    RevisionStatus = "PENDING"
+	RevisionStatusApproved   RevisionStatus = "APPROVED"
+	RevisionStatusRejected   RevisionStatus = "REJECTED"
+	RevisionStatusSuperseded RevisionStatus = "SUPERSEDED" // Sustituida por un envío posterior del profesional
+)
+
+// Marca automática cuando cambia el número de colegiado (se comprueba a mano)
+const RevisionFlagLicense = "license_number"
+
+// ProfileRevision es una propuesta de cambio en los campos públicos de una ficha.
+// Mientras está pendiente, la ficha sigue mostrando la última versión aprobada.
+type ProfileRevision struct {
+	ID          uuid.UUID      `json:"id"`
+	EntityID    uuid.UUID      `json:"entity_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	Name        string         `json:"name"`
+	EntityType  string         `json:"entity_type"`
+	ProfileData ProfileData    `json:"profile_data"`
+	Changes     []string       `json:"changes"` // Campos públicos que difieren de la versión aprobada
+	Flags       []string       `json:"flags"`   // link, phone, email, profanity, license_number
+	Status      RevisionStatus `json:"status"`
+	Reason      string         `json:"reason,omitempty"` // Motivo del rechazo
+	ReviewedBy  *uuid.UUID     `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time     `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+
+	Current *ProfessionalEntity `json:"current,omitempty"` // Versión publicada, solo en el detalle para el moderador
+}
+
+// moderatedField es un campo visible en la ficha pública. inData indica si vive
+// dentro de profile_data (con esa clave JSON) o es una columna de la entidad.
+type moderatedField struct {
+	key    string
+	inData bool
+	get    func(e *ProfessionalEntity) any
+	set    func(dst, src *ProfessionalEntity)
+}
+
+// Los campos que no están aquí (horario, festivos, tarifas, seguros) se aplican al momento
+var moderatedFields = []moderatedField{
+	{"name", false, func(e *ProfessionalEntity) any { return e.Name }, func(d, s *ProfessionalEntity) { d.Name = s.Name }},
+	{"entity_type", false, func(e *ProfessionalEntity) any { return e.EntityType }, func(d, s *ProfessionalEntity) { d.EntityType = s.EntityType }},
+	{"license_number", true, func(e *ProfessionalEntity) any { return e.ProfileData.LicenseNumber }, func(d, s *ProfessionalEntity) { d.ProfileData.LicenseNumber = s.ProfileData.LicenseNumber }},
+	{"bio", true, func(e *ProfessionalEntity) any { return e.ProfileData.Bio }, func(d, s *ProfessionalEntity) { d.ProfileData.Bio = s.ProfileData.Bio }},
+	{"logo_url", true, func(e *ProfessionalEntity) any { return e.ProfileData.LogoURL }, func(d, s *ProfessionalEntity) { d.ProfileData.LogoURL = s.ProfileData.LogoURL }},
+	{"addresses", true, func(e *ProfessionalEntity) any { return e.ProfileData.Addresses }, func(d, s *ProfessionalEntity) { d.ProfileData.Addresses = s.ProfileData.Addresses }},
+	{"contact", true, func(e *ProfessionalEntity) any { return e.ProfileData.Contact }, func(d, s *ProfessionalEntity) { d.ProfileData.Contact = s.ProfileData.Contact }},
+	{"specialization", true, func(e *ProfessionalEntity) any { return e.ProfileData.Specialization }, func(d, s *ProfessionalEntity) { d.ProfileData.Specialization = s.ProfileData.Specialization }},
+	{"specialties", true, func(e *ProfessionalEntity) any { return e.ProfileData.Specialties }, func(d, s *ProfessionalEntity) { d.ProfileData.Specialties = s.ProfileData.Specialties }},
+}
+
+// ModeratedChanges devuelve los campos públicos que cambian entre e y next
+func (e *ProfessionalEntity) ModeratedChanges(next *ProfessionalEntity) []string {
+	changes := []string{}
+	for _, f := range moderatedFields {
+		if !sameJSON(f.get(e), f.get(next)) {
+			changes = append(changes, f.key)
+		}
+	}
+	return changes
+}
+
+// CopyModerated sobrescribe los campos públicos de e con los de src
+func (e *ProfessionalEntity) CopyModerated(src *ProfessionalEntity) {
+	for _, f := range moderatedFields {
+		f.set(e, src)
+	}
+}
+
+// ModeratedProfileData devuelve solo las claves moderadas de profile_data, para
+// mezclarlas con jsonb || sin pisar horarios o tarifas guardados mientras tanto
+func ModeratedProfileData(p ProfileData) map[string]any {
+	e := &ProfessionalEntity{ProfileData: p}
+	data := map[string]any{}
+	for _, f := range moderatedFields {
+		if f.inData {
+			data[f.key] = f.get(e)
+		}
+	}
+	return data
+}
+
+// sameJSON compara por su forma JSON; null, "", [] y {} se consideran iguales
+// porque el formulario y la base de datos no siempre representan igual lo vacío
+func sameJSON(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return string(ja) == string(jb) || (emptyJSON(ja) && emptyJSON(jb))
+}
+
+func emptyJSON(b []byte) bool {
+	switch string(b) {
+	case "null", `""`, "[]", "{}":
+		return true
+	}
+	return false
+}
+
+type RevisionRepository interface {
+	// CreateRevision guarda la revisión como PENDING y marca SUPERSEDED la pendiente anterior
+	CreateRevision(ctx context.Context, rev *ProfileRevision) error
+	// DiscardPendingRevision marca SUPERSEDED la revisión pendiente (el profesional ha deshecho sus cambios)
+	DiscardPendingRevision(ctx context.Context, entityID uuid.UUID) error
+	GetRevision(ctx context.Context, id uuid.UUID) (*ProfileRevision, error)
+	// GetLatestRevision devuelve el último envío de la ficha, en cualquier estado
+	GetLatestRevision(ctx context.Context, entityID uuid.UUID) (*ProfileRevision, error)
+	ListRevisions(ctx context.Context, status RevisionStatus, limit, offset int) (int, []ProfileRevision, error)
+	// ApproveRevision publica la revisión en la ficha (con el slug base indicado) en una transacción
+	ApproveRevision(ctx context.Context, id, reviewerID uuid.UUID, slugBase string) error
+	RejectRevision(ctx context.Context, id, reviewerID uuid.UUID, reason string) error
+}
+
+type ModerationService interface {
+	ListRevisions(ctx context.Context, status RevisionStatus, limit, offset int) (int, []ProfileRevision, error)
+	GetRevision(ctx context.Context, id uuid.UUID) (*ProfileRevision, error)
+	ApproveRevision(ctx context.Context, adminID, id uuid.UUID) (*ProfileRevision, error)
+	RejectRevision(ctx context.Context, adminID, id uuid.UUID, reason string) (*ProfileRevision, error)
+}
@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScheduleTimezone es la zona horaria en la que se interpretan todos los horarios
+const ScheduleTimezone = "Europe/Madrid"
+
+// WeekdayKeys son las claves de ProfileData.WorkingHours, indexadas por time.Weekday
+var WeekdayKeys = [7]string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+var (
+	scheduleLocOnce sync.Once
+	scheduleLoc     *time.Location
+)
+
+// ScheduleLocation devuelve Europe/Madrid (o UTC si el sistema no trae la base de zonas;
+// el binario importa time/tzdata para que no ocurra)
+func ScheduleLocation() *time.Location {
+	scheduleLocOnce.Do(func() {
+		loc, err := time.LoadLocation(ScheduleTimezone)
+		if err != nil {
+			loc = time.UTC
+		}
+		scheduleLoc = loc
+	})
+	return scheduleLoc
+}
+
+// TimeRange es un turno "HH:MM"-"HH:MM". Se admite "24:00" como cierre y, si el
+// cierre es anterior o igual a la apertura, el turno cruza la medianoche.
+type TimeRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Holiday es un día de cierre excepcional (festivo, vacaciones...) en formato YYYY-MM-DD
+type Holiday struct {
+	Date string `json:"date"`
+	Name string `json:"name,omitempty"`
+}
+
+// Ranges devuelve los turnos válidos del día: Shifts si existen o, por compatibilidad,
+// el par Start/End. Los turnos con horas no reconocibles se ignoran.
+func (d WorkingDay) Ranges() []TimeRange {
+	if !d.Active {
+		return nil
+	}
+	candidates := d.Shifts
+	if len(candidates) == 0 {
+		candidates = []TimeRange{{Start: d.Start, End: d.End}}
+	}
+
+	var valid []TimeRange
+	for _, r := range candidates {
+		_, okStart := ParseClock(r.Start)
+		_, okEnd := ParseClock(r.End)
+		if okStart && okEnd {
+			valid = append(valid, r)
+		}
+	}
+	return valid
+}
+
+// ParseClock convierte "HH:MM" en minutos desde medianoche (0..1440)
+func ParseClock(s string) (int, bool) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 2 || len(parts[0]) < 1 || len(parts[0]) > 2 || len(parts[1]) != 2 ||
+		!isDigits(parts[0]) || !isDigits(parts[1]) {
+		return 0, false
+	}
+	h, errH := strconv.Atoi(parts[0])
+	m, errM := strconv.Atoi(parts[1])
+	if errH != nil || errM != nil || h < 0 || m < 0 || m > 59 {
+		return 0, false
+	}
+	if h > 24 || (h == 24 && m != 0) {
+		return 0, false
+	}
+	return h*60 + m, true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Schedule agrupa el horario semanal y los cierres de un profesional
+type Schedule struct {
+	Hours    map[string]WorkingDay
+	Holidays []Holiday
+}
+
+// Schedule extrae el horario de la ficha
+func (p ProfileData) Schedule() Schedule {
+	return Schedule{Hours: p.WorkingHours, Holidays: p.Holidays}
+}
+
+// Known indica si hay al menos un turno válido; si no, no podemos decir si está abierto
+func (s Schedule) Known() bool {
+	for _, day := range s.Hours {
+		if len(day.Ranges()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Schedule) isHoliday(day time.Time) bool {
+	date := day.Format("2006-01-02")
+	for _, h := range s.Holidays {
+		if h.Date == date {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpenAt indica si el profesional está abierto en el instante t (hora de Madrid).
+// Un festivo cierra el día natural completo, incluida la parte de un turno nocturno
+// que empezó la víspera. Es la misma regla que aplica el filtro SQL.
+func (s Schedule) IsOpenAt(t time.Time) bool {
+	local := t.In(ScheduleLocation())
+	if s.isHoliday(local) {
+		return false
+	}
+	minute := local.Hour()*60 + local.Minute()
+
+	// Turnos de hoy
+	for _, r := range s.Hours[WeekdayKeys[local.Weekday()]].Ranges() {
+		start, _ := ParseClock(r.Start)
+		end, _ := ParseClock(r.End)
+		if end > start && minute >= start && minute < end {
+			return true
+		}
+		if end <= start && minute >= start {
+			return true
+		}
+	}
+
+	// Turnos nocturnos de ayer que se prolongan hasta hoy
+	yesterday := WeekdayKeys[(local.Weekday()+6)%7]
+	for _, r := range s.Hours[yesterday].Ranges() {
+		start, _ := ParseClock(r.Start)
+		end, _ := ParseClock(r.End)
+		if end <= start && minute < end {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpening devuelve la próxima apertura posterior a t dentro de las dos semanas
+// siguientes, o nil si ya está abierto o no hay horario conocido.
+func (s Schedule) NextOpening(t time.Time) *time.Time {
+	if !s.Known() || s.IsOpenAt(t) {
+		return nil
+	}
+
+	local := t.In(ScheduleLocation())
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+
+	for offset := 0; offset <= 14; offset++ {
+		day := midnight.AddDate(0, 0, offset)
+		if s.isHoliday(day) {
+			continue
+		}
+
+		var best *time.Time
+		for _, r := range s.Hours[WeekdayKeys[day.Weekday()]].Ranges() {
+			start, _ := ParseClock(r.Start)
+			opening := time.Date(day.Year(), day.Month(), day.Day(), start/60, start%60, 0, 0, day.Location())
+			if opening.After(local) && (best == nil || opening.Before(*best)) {
+				o := opening
+				best = &o
+			}
+		}
+		if best != nil {
+			return best
+		}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrScheduleUnparseable indica que el texto del horario no se ha podido interpretar
+var ErrScheduleUnparseable = errors.New("horario no reconocido")
+
+// Nombres y abreviaturas de días aceptados (sin tildes), indexados por time.Weekday.
+// Las iniciales siguen la convención española L M X J V S D.
+var dayAliases = [7][]string{
+	{"domingos", "domingo", "sunday", "dom", "sun", "do", "d"},
+	{"lunes", "monday", "lun", "mon", "lu", "l"},
+	{"martes", "tuesday", "mar", "tue", "ma", "m"},
+	{"miercoles", "wednesday", "mie", "wed", "mi", "x"},
+	{"jueves", "thursday", "jue", "thu", "ju", "j"},
+	{"viernes", "friday", "vie", "fri", "vi", "v"},
+	{"sabados", "sabado", "saturday", "sab", "sat", "sa", "s"},
+}
+
+var (
+	dayPattern = buildDayPattern()
+
+	// Orden de la alternativa = prioridad: 24h antes que un tramo horario, rangos de días antes que días sueltos
+	scheduleToken = regexp.MustCompile(`(?P<allday>\b(?:abierto\s+)?24\s*(?:h|hrs|horas|hours)\b|\bopen\s+24\s+hours\b)` +
+		`|(?P<range>\b(\d{1,2})(?:[:.h](\d{2}))?\s*h?\s*(am|pm)?\s*(?:-|\ba\b|\bhasta\b|\bto\b)\s*(\d{1,2})(?:[:.h](\d{2}))?\s*h?\s*(am|pm)?)` +
+		`|(?P<closed>\b(?:cerrado|closed)\b)` +
+		`|(?P<everyday>\b(?:todos\s+los\s+dias|diario|every\s*day|daily)\b)` +
+		`|(?P<weekend>\b(?:fines?\s+de\s+semana|weekends?)\b)` +
+		`|(?P<dayrange>\b(` + dayPattern + `)\s*(?:-|\ba\b|\bal\b|\bto\b)\s*(` + dayPattern + `)\b)` +
+		`|(?P<day>\b(` + dayPattern + `)\b)`)
+
+	// Palabras de relleno que pueden quedar entre tokens sin que el horario sea ilegible
+	scheduleFiller = regexp.MustCompile(`\b(?:y|e|de|del|desde|los|las|el|and|horario|horarios|hours|h|hrs|festivos|festivo|feriados|holidays|urgencias|tambien|only|solo|previa|cita)\b|[\s;,:.()/\-|]+`)
+
+	scheduleAccents = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u",
+		" ", " ", " ", " ", " ", " ", "–", "-", "—", "-", "a.m.", "am", "p.m.", "pm")
+)
+
+func buildDayPattern() string {
+	var all []string
+	for _, aliases := range dayAliases {
+		all = append(all, aliases...)
+	}
+	// Las alternativas largas primero para que "mar" no se quede en "m"
+	for i := 0; i < len(all); i++ {
+		for j := i + 1; j < len(all); j++ {
+			if len(all[j]) > len(all[i]) {
+				all[i], all[j] = all[j], all[i]
+			}
+		}
+	}
+	return strings.Join(all, "|")
+}
+
+func lookupDay(name string) int {
+	for day, aliases := range dayAliases {
+		for _, a := range aliases {
+			if a == name {
+				return day
+			}
+		}
+	}
+	return -1
+}
+
+// ParseSchedule interpreta horarios en texto libre como los de Google Maps o los
+// directorios de colegios: "L-V 9:00-14:00 y 17:00-20:30, S 10-13",
+// "lunes: 10:00–13:00, 16:30–19:00; sábado: Cerrado", "Monday: 9:30 AM – 8:30 PM",
+// "Abierto 24 horas" o "24h". Los días que el texto no menciona quedan cerrados.
+// Un texto vacío devuelve (nil, nil): no hay horario, pero tampoco es un error.
+func ParseSchedule(text string) (map[string]WorkingDay, error) {
+	normalized := strings.TrimSpace(scheduleAccents.Replace(strings.ToLower(text)))
+	if normalized == "" {
+		return nil, nil
+	}
+
+	shifts := make([][]TimeRange, 7)
+	mentioned := make([]bool, 7)
+	var current []int
+	consumed := false // El grupo de días actual ya tiene horas o "cerrado"
+	lastWasDay := false
+
+	allDays := []int{0, 1, 2, 3, 4, 5, 6}
+	setDays := func(days []int) {
+		if consumed || !lastWasDay {
+			current = nil
+		}
+		current = append(current, days...)
+		for _, d := range days {
+			mentioned[d] = true
+		}
+		consumed = false
+		lastWasDay = true
+	}
+
+	var leftover strings.Builder // Texto entre tokens: solo debería quedar relleno
+	last := 0
+	for _, m := range scheduleToken.FindAllStringSubmatchIndex(normalized, -1) {
+		leftover.WriteString(normalized[last:m[0]] + " ")
+		last = m[1]
+
+		// sub devuelve el grupo n-ésimo ("" si no participa); matched, si el grupo con nombre casó
+		sub := func(i int) string {
+			if m[2*i] < 0 {
+				return ""
+			}
+			return normalized[m[2*i]:m[2*i+1]]
+		}
+		matched := func(name string) (int, bool) {
+			i := scheduleToken.SubexpIndex(name)
+			return i, m[2*i] >= 0
+		}
+
+		if _, ok := matched("allday"); ok {
+			// "24h" tras un grupo ya completo ("L-V 9-14, S 10-13, 24h") no dice a qué días se
+			// refiere: se rechaza en vez de pisar los tramos explícitos. Sin días, son todos.
+			if consumed {
+				return nil, fmt.Errorf("%w: \"24h\" tras horarios explícitos en %q", ErrScheduleUnparseable, text)
+			}
+			if current == nil {
+				current = allDays
+				for _, d := range allDays {
+					mentioned[d] = true
+				}
+			}
+			for _, d := range current {
+				shifts[d] = []TimeRange{{Start: "00:00", End: "24:00"}}
+			}
+			consumed, lastWasDay = true, false
+		} else if i, ok := matched("range"); ok {
+			if current == nil {
+				return nil, fmt.Errorf("%w: tramo horario sin días en %q", ErrScheduleUnparseable, text)
+			}
+			r, err := parseTimeRange(sub(i+1), sub(i+2), sub(i+3), sub(i+4), sub(i+5), sub(i+6))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v en %q", ErrScheduleUnparseable, err, text)
+			}
+			for _, d := range current {
+				shifts[d] = append(shifts[d], r)
+			}
+			consumed, lastWasDay = true, false
+		} else if _, ok := matched("closed"); ok {
+			for _, d := range current {
+				shifts[d] = nil
+			}
+			consumed, lastWasDay = true, false
+		} else if _, ok := matched("everyday"); ok {
+			setDays(allDays)
+		} else if _, ok := matched("weekend"); ok {
+			setDays([]int{6, 0})
+		} else if i, ok := matched("dayrange"); ok {
+			from, to := lookupDay(sub(i+1)), lookupDay(sub(i+2))
+			var days []int
+			for d := from; ; d = (d + 1) % 7 {
+				days = append(days, d)
+				if d == to {
+					break
+				}
+			}
+			setDays(days)
+		} else if i, ok := matched("day"); ok {
+			setDays([]int{lookupDay(sub(i + 1))})
+		}
+	}
+	leftover.WriteString(normalized[last:])
+
+	if rest := strings.TrimSpace(scheduleFiller.ReplaceAllString(leftover.String(), " ")); rest != "" {
+		return nil, fmt.Errorf("%w: no se entiende %q en %q", ErrScheduleUnparseable, rest, text)
+	}
+
+	hours := make(map[string]WorkingDay, 7)
+	anyOpen := false
+	for d := 0; d < 7; d++ {
+		day := WorkingDay{}
+		if len(shifts[d]) > 0 {
+			day = WorkingDay{
+				Active: true,
+				Start:  shifts[d][0].Start,
+				End:    shifts[d][len(shifts[d])-1].End,
+				Shifts: shifts[d],
+			}
+			anyOpen = true
+		}
+		hours[WeekdayKeys[d]] = day
+	}
+
+	if !anyOpen {
+		anyMentioned := false
+		for _, m := range mentioned {
+			anyMentioned = anyMentioned || m
+		}
+		if !anyMentioned {
+			return nil, fmt.Errorf("%w: sin días ni horas en %q", ErrScheduleUnparseable, text)
+		}
+	}
+	return hours, nil
+}
+
+// parseTimeRange construye un TimeRange a partir de las piezas capturadas.
+// En formato de 12 horas la primera hora hereda el AM/PM de la segunda si no lo
+// trae ("5:00 – 7:30 PM"), salvo que eso la deje detrás del cierre ("11:00 – 1:30 PM").
+func parseTimeRange(h1, m1, ampm1, h2, m2, ampm2 string) (TimeRange, error) {
+	end, err := clockMinutes(h2, m2, ampm2)
+	if err != nil {
+		return TimeRange{}, err
+	}
+
+	inherited := ampm1
+	if inherited == "" {
+		inherited = ampm2
+	}
+	start, err := clockMinutes(h1, m1, inherited)
+	if err != nil {
+		return TimeRange{}, err
+	}
+	if ampm1 == "" && ampm2 != "" && start > end {
+		if start, err = clockMinutes(h1, m1, ""); err != nil {
+			return TimeRange{}, err
+		}
+	}
+
+	return TimeRange{Start: formatClock(start), End: formatClock(end)}, nil
+}
+
+func clockMinutes(hour, minute, ampm string) (int, error) {
+	h, _ := strconv.Atoi(hour)
+	m := 0
+	if minute != "" {
+		m, _ = strconv.Atoi(minute)
+	}
+
+	switch ampm {
+	case "am":
+		if h == 12 {
+			h = 0
+		}
+	case "pm":
+		if h < 12 {
+			h += 12
+		}
+	}
+
+	if h > 24 || m > 59 || (h == 24 && m > 0) {
+		return 0, fmt.Errorf("hora fuera de rango %s:%s", hour, minute)
+	}
+	return h*60 + m, nil
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
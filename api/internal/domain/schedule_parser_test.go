@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseSchedule(t *testing.T) {
+	closed := WorkingDay{}
+	open := func(shifts ...TimeRange) WorkingDay {
+		return WorkingDay{Active: true, Start: shifts[0].Start, End: shifts[len(shifts)-1].End, Shifts: shifts}
+	}
+	morning := TimeRange{Start: "09:00", End: "14:00"}
+	evening := TimeRange{Start: "17:00", End: "20:30"}
+	allDay := TimeRange{Start: "00:00", End: "24:00"}
+
+	tests := []struct {
+		name    string
+		text    string
+		want    map[string]WorkingDay
+		wantErr bool
+	}{
+		{
+			name: "turno partido entre semana y sábado",
+			text: "L-V 9:00-14:00 y 17:00-20:30, S 10-13",
+			want: map[string]WorkingDay{
+				"monday": open(morning, evening), "tuesday": open(morning, evening), "wednesday": open(morning, evening),
+				"thursday": open(morning, evening), "friday": open(morning, evening),
+				"saturday": open(TimeRange{Start: "10:00", End: "13:00"}), "sunday": closed,
+			},
+		},
+		{
+			name: "24h sin días",
+			text: "Abierto 24 horas",
+			want: map[string]WorkingDay{
+				"monday": open(allDay), "tuesday": open(allDay), "wednesday": open(allDay), "thursday": open(allDay),
+				"friday": open(allDay), "saturday": open(allDay), "sunday": open(allDay),
+			},
+		},
+		{
+			name: "24h de un grupo de días",
+			text: "S-D 24h",
+			want: map[string]WorkingDay{
+				"monday": closed, "tuesday": closed, "wednesday": closed, "thursday": closed,
+				"friday": closed, "saturday": open(allDay), "sunday": open(allDay),
+			},
+		},
+		{
+			// El "24h" final no dice a qué días se refiere: no puede pisar los tramos anteriores
+			name:    "24h tras horarios explícitos",
+			text:    "L-V 9:00-14:00 y 17:00-20:30, S 10-13, 24h",
+			wantErr: true,
+		},
+		{
+			name:    "texto ilegible",
+			text:    "consultar por teléfono",
+			wantErr: true,
+		},
+		{
+			name: "vacío",
+			text: "  ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSchedule(tt.text)
+			if tt.wantErr {
+				if !errors.Is(err, ErrScheduleUnparseable) {
+					t.Fatalf("ParseSchedule(%q) = %v, %v; se esperaba ErrScheduleUnparseable", tt.text, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q): %v", tt.text, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSchedule(%q) =\n%v\nse esperaba\n%v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSessionNotFound     = errors.New("sesión no encontrada")
+	ErrRefreshTokenInvalid = errors.New("refresh token inválido o caducado")
+	ErrRefreshTokenReused  = errors.New("refresh token reutilizado: sesión revocada")
+)
+
+// Motivos de revocación de una sesión
+const (
+	SessionRevokedLogout    = "logout"
+	SessionRevokedLogoutAll = "logout_all"
+	SessionRevokedByUser    = "revoked"
+	SessionRevokedReuse     = "reuse"
+	SessionRevokedPassword  = "password" // Contraseña cambiada o recuperada
+)
+
+// Session es un dispositivo con la sesión iniciada
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"-"`
+	Role       Role      `json:"-"` // Del usuario, para emitir el access token al refrescar
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // Es la sesión del token con el que se consulta
+}
+
+// SessionMeta identifica el dispositivo que hace login o refresca
+type SessionMeta struct {
+	UserAgent string
+	IP        string
+}
+
+// TokenPair es lo que recibe el cliente al hacer login o refrescar
+type TokenPair struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresIn    int       `json:"expires_in"` // Segundos de vida del access token
+	SessionID    uuid.UUID `json:"session_id"`
+}
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, s *Session, tokenHash string) error
+	// RotateRefreshToken consume oldHash y registra newHash en la misma sesión,
+	// alargando su caducidad. Un token ya consumido revoca la sesión (ErrRefreshTokenReused),
+	// salvo que se usara hace menos de reuseGrace desde el mismo dispositivo (user agent
+	// e IP del último refresco): entonces es ErrRefreshTokenInvalid.
+	RotateRefreshToken(ctx context.Context, oldHash, newHash string, meta SessionMeta, expiresAt time.Time, reuseGrace time.Duration) (*Session, error)
+	RevokeSessionByToken(ctx context.Context, tokenHash, reason string) error
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID, reason string) error
+	// RevokeAllSessions cierra todas las sesiones del usuario salvo except (uuid.Nil = ninguna)
+	RevokeAllSessions(ctx context.Context, userID, except uuid.UUID, reason string) (int, error)
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]Session, error)
+}
+
+type SessionService interface {
+	Start(ctx context.Context, u *User, meta SessionMeta) (*TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string, meta SessionMeta) (*TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+	LogoutAll(ctx context.Context, userID uuid.UUID) (int, error)
+	ListSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
+}
@@ -30,6 +30,7 @@ type User struct {
 	VerificationCode   string    `json:"verification_code"`
 	SubscriptionStatus string    `json:"subscription_status"`
 	TrialEndsAt        *time.Time `json:"trial_ends_at"`  
+	Locale             string    `json:"locale"` // Idioma de los correos ("es", "en")
 
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
@@ -83,6 +84,13 @@ type Appointment struct {
 	ProfessionalName string `json:"professional_name,omitempty"`
 }
 
+// AppointmentNotice es la cita con los datos necesarios para avisar al dueño por correo
+type AppointmentNotice struct {
+	Appointment
+	OwnerEmail  string
+	OwnerLocale string
+}
+
 type MedicalHistory struct {
 	ID             uuid.UUID `json:"id"`
 	PetID          uuid.UUID `json:"pet_id"`
@@ -100,7 +108,6 @@ type UserRepository interface {
 	CreateUser(ctx context.Context, u *User) error
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (*User, error)
-	VerifyUser(ctx context.Context, email string) error
 
 	// Perfil de Dueño (Método unificado que reemplaza a UpsertOwnerAccount)
 	UpsertOwnerProfile(ctx context.Context, userID string, name, phone, city, address, cp string, contact interface{}) error
@@ -124,6 +131,7 @@ type UserRepository interface {
 	GetAppointmentsByProfessionalID(ctx context.Context, profID uuid.UUID) ([]Appointment, error)
 	UpdateAppointmentStatus(ctx context.Context, appID uuid.UUID, status string) error // Aprovechamos para añadir esta
 	RescheduleAppointment(ctx context.Context, appID uuid.UUID, newDate time.Time, notes string) error
+	GetAppointmentNotice(ctx context.Context, appID uuid.UUID) (*AppointmentNotice, error)
 
 	// NOTA: Si borraste UpsertOwnerAccount e IsSubscriptionActive del repositorio,
 	// NO pueden estar aquí. Si los necesitas en el futuro, habrá que implementarlos en el repo.
@@ -132,11 +140,14 @@ type UserRepository interface {
 }
 
 type AuthService interface {
-	Register(ctx context.Context, name, email, password, role, plan string, hasTrial bool) error
-	Login(ctx context.Context, email, password string) (string, error)
+	// Register crea la cuenta; locale es el idioma de sus correos (ver mailer.Locale)
+	Register(ctx context.Context, name, email, password, role, plan string, hasTrial bool, locale string) error
+	Login(ctx context.Context, email, password string, meta SessionMeta) (*TokenPair, error)
 	Verify(ctx context.Context, email, code string) error
+	ResendVerification(ctx context.Context, email string) error
 	GetUserByID(ctx context.Context, id uuid.UUID) (*User, error)
-	UpsertProfessionalProfile(ctx context.Context, userID uuid.UUID, p *ProfessionalEntity) error
+	// UpsertProfessionalProfile devuelve la revisión pendiente si hay cambios públicos que moderar
+	UpsertProfessionalProfile(ctx context.Context, userID uuid.UUID, p *ProfessionalEntity) (*ProfileRevision, error)
 	GetProfessionalProfileByUserID(ctx context.Context, userID uuid.UUID) (*ProfessionalEntity, error)
 }
 
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrVerificationCodeInvalid = errors.New("el código de verificación no es correcto")
+	ErrVerificationCodeExpired = errors.New("el código de verificación ha caducado; solicita uno nuevo")
+	ErrVerificationLocked      = errors.New("demasiados intentos fallidos; solicita un código nuevo")
+	ErrVerificationThrottled   = errors.New("espera antes de pedir otro código")
+	ErrEmailNotVerified        = errors.New("la cuenta no está verificada")
+)
+
+// VerificationThrottledError indica cuánto falta para poder pedir otro código
+type VerificationThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *VerificationThrottledError) Error() string {
+	return fmt.Sprintf("%v (%s)", ErrVerificationThrottled, e.RetryAfter.Round(time.Second))
+}
+
+func (e *VerificationThrottledError) Is(target error) bool {
+	return target == ErrVerificationThrottled
+}
+
+// Verification es el estado del código de verificación de una cuenta
+type Verification struct {
+	UserID     uuid.UUID
+	Email      string
+	Name       *string
+	Locale     string
+	IsVerified bool
+	Code       string
+	ExpiresAt  *time.Time
+	Attempts   int
+}
+
+type VerificationRepository interface {
+	GetVerification(ctx context.Context, email string) (*Verification, error)
+	// SetVerificationCode sustituye el código, reinicia los intentos y registra el envío
+	SetVerificationCode(ctx context.Context, userID uuid.UUID, code string, expiresAt time.Time) error
+	// ConsumeVerificationAttempt gasta un intento antes de comparar el código, de forma
+	// atómica, y devuelve el código vigente con los intentos ya contados. Si no quedan
+	// intentos devuelve ErrVerificationLocked.
+	ConsumeVerificationAttempt(ctx context.Context, userID uuid.UUID, maxAttempts int) (*Verification, error)
+	// CompleteVerification marca la cuenta como verificada y borra el código
+	CompleteVerification(ctx context.Context, userID uuid.UUID) error
+	// RecentVerificationSends devuelve los envíos desde since, el más reciente primero
+	RecentVerificationSends(ctx context.Context, userID uuid.UUID, since time.Time) ([]time.Time, error)
+}
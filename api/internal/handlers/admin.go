@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/responses"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	adminDefaultLimit = 20
+	adminMaxLimit     = 100
+)
+
+// AdminHandler gestiona cuentas y fichas desde el panel (solo ADMIN)
+type AdminHandler struct {
+	Service domain.AdminService
+}
+
+func NewAdminHandler(service domain.AdminService) *AdminHandler {
+	return &AdminHandler{Service: service}
+}
+
+// ListUsers: GET /api/admin/users?q=&role=&verified=&subscription_status=&limit=&offset=
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := domain.UserFilter{
+		Query:              q.Get("q"),
+		Role:               domain.Role(strings.ToUpper(q.Get("role"))),
+		SubscriptionStatus: strings.ToLower(q.Get("subscription_status")),
+	}
+	var ok bool
+	if f.Verified, ok = boolParam(w, q, "verified"); !ok {
+		return
+	}
+	f.Limit, f.Offset = limitOffsetParams(q, adminDefaultLimit, adminMaxLimit)
+
+	total, users, err := h.Service.SearchUsers(r.Context(), f)
+	if err != nil {
+		adminError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, map[string]interface{}{
+		"total": total,
+		"users": users,
+	})
+}
+
+// GetUser: GET /api/admin/users/{userID}
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidParam(w, r, "userID")
+	if !ok {
+		return
+	}
+
+	u, err := h.Service.GetUser(r.Context(), id)
+	if err != nil {
+		adminError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, u)
+}
+
+// UpdateSubscription: PATCH /api/admin/users/{userID}/subscription
+// con {"subscription_status": "premium", "trial_ends_at": "2026-12-31T00:00:00Z" | null}.
+// Los campos que no vienen no se tocan; trial_ends_at a null quita el periodo de prueba.
+func (h *AdminHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+	id, ok := uuidParam(w, r, "userID")
+	if !ok {
+		return
+	}
+
+	var req map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		responses.Error(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	var change domain.SubscriptionChange
+	if raw, found := req["subscription_status"]; found {
+		var status string
+		if err := json.Unmarshal(raw, &status); err != nil {
+			responses.Error(w, http.StatusBadRequest, "subscription_status debe ser un texto")
+			return
+		}
+		change.Status = &status
+	}
+	if raw, found := req["trial_ends_at"]; found {
+		var trialEndsAt *time.Time
+		if err := json.Unmarshal(raw, &trialEndsAt); err != nil {
+			responses.Error(w, http.StatusBadRequest, "trial_ends_at debe ser una fecha RFC 3339 o null")
+			return
+		}
+		change.TrialEndsAt = &trialEndsAt
+	}
+	if change.Status == nil && change.TrialEndsAt == nil {
+		responses.Error(w, http.StatusBadRequest, "Indica subscription_status o trial_ends_at")
+		return
+	}
+
+	u, err := h.Service.UpdateSubscription(r.Context(), adminID, id, change)
+	if err != nil {
+		adminError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, u)
+}
+
+// ResendVerification: POST /api/admin/users/{userID}/resend-verification
+func (h *AdminHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+	id, ok := uuidParam(w, r, "userID")
+	if !ok {
+		return
+	}
+
+	if err := h.Service.ResendVerification(r.Context(), adminID, id); err != nil {
+		adminError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, map[string]string{
+		"message": "Código de verificación reenviado",
+	})
+}
+
+// ListEntities: GET /api/admin/entities?q=&status=&active=&limit=&offset= (incluye las desactivadas)
+func (h *AdminHandler) ListEntities(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := domain.EntityFilter{
+		Query:  q.Get("q"),
+		Status: strings.ToUpper(q.Get("status")),
+	}
+	var ok bool
+	if f.Active, ok = boolParam(w, q, "active"); !ok {
+		return
+	}
+	f.Limit, f.Offset = limitOffsetParams(q, adminDefaultLimit, adminMaxLimit)
+
+	total, entities, err := h.Service.SearchEntities(r.Context(), f)
+	if err != nil {
+		adminError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, map[string]interface{}{
+		"total":    total,
+		"entities": entities,
+	})
+}
+
+// SetEntityActive: PATCH /api/admin/entities/{entityID}/active con {"is_active": false}
+func (h *AdminHandler) SetEntityActive(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+	id, ok := uuidParam(w, r, "entityID")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		IsActive *bool `json:"is_active"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.IsActive == nil {
+		responses.Error(w, http.StatusBadRequest, "is_active es obligatorio")
+		return
+	}
+
+	entity, err := h.Service.SetEntityActive(r.Context(), adminID, id, *req.IsActive)
+	if err != nil {
+		adminError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, entity)
+}
+
+// SetEntityStatus: PATCH /api/admin/entities/{entityID}/status con {"status": "VERIFIED"}.
+// PENDING no se asigna a mano: solo lo tienen las fichas nuevas hasta su primera aprobación.
+func (h *AdminHandler) SetEntityStatus(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+	id, ok := uuidParam(w, r, "entityID")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		responses.Error(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	entity, err := h.Service.SetEntityStatus(r.Context(), adminID, id, req.Status)
+	if err != nil {
+		adminError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, entity)
+}
+
+// uuidParam lee un ID de la ruta; si no es válido ya ha respondido
+func uuidParam(w http.ResponseWriter, r *http.Request, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, name))
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "ID inválido")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// boolParam lee un filtro opcional true/false; nil si no viene
+func boolParam(w http.ResponseWriter, q url.Values, name string) (*bool, bool) {
+	raw := q.Get(name)
+	if raw == "" {
+		return nil, true
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, name+" debe ser true o false")
+		return nil, false
+	}
+	return &v, true
+}
+
+// limitOffsetParams lee la paginación clásica con un límite por defecto y un máximo
+func limitOffsetParams(q url.Values, defaultLimit, maxLimit int) (int, int) {
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	offset, err := strconv.Atoi(q.Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// adminError traduce los errores del dominio a códigos HTTP
+func adminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrProfileNotFound):
+		responses.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrAdminInvalid):
+		responses.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrAlreadyVerified), errors.Is(err, domain.ErrEntityStatusOwner):
+		responses.Error(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("❌ Error en administración: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "Error al procesar la petición")
+	}
+}
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
 	"veterimap-api/internal/auth"
@@ -29,6 +31,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		Role         domain.Role `json:"role"`
 		SelectedPlan string      `json:"selected_plan"` // Recibimos del front
 		HasTrial     bool        `json:"has_trial"`
+		Locale       string      `json:"locale"` // Opcional: si no llega, se usa Accept-Language
 	}
 
 	// 1. Decodificar el JSON del frontend
@@ -39,7 +42,11 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	// 2. Llamar al servicio de autenticación
 	// Pasamos req.Name como primer argumento según la nueva firma del Service
-	if err := h.Service.Register(r.Context(), req.Name, req.Email, req.Password, string(req.Role), req.SelectedPlan, req.HasTrial); err != nil {
+	locale := req.Locale
+	if locale == "" {
+		locale = r.Header.Get("Accept-Language")
+	}
+	if err := h.Service.Register(r.Context(), req.Name, req.Email, req.Password, string(req.Role), req.SelectedPlan, req.HasTrial, locale); err != nil {
 
 		if err == domain.ErrUserAlreadyExists {
 			responses.Error(w, http.StatusConflict, "El correo ya está registrado")
@@ -61,7 +68,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// Login: Autentica y devuelve el token JWT
+// Login: Autentica y devuelve el access token (JWT) y el refresh token de la sesión
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Email    string `json:"email"`
@@ -73,15 +80,22 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.Service.Login(r.Context(), req.Email, req.Password)
+	pair, err := h.Service.Login(r.Context(), req.Email, req.Password, sessionMeta(r))
 	if err != nil {
+		if errors.Is(err, domain.ErrEmailNotVerified) {
+			verificationError(w, err)
+			return
+		}
+		if !errors.Is(err, domain.ErrInvalidCredentials) {
+			log.Printf("❌ Error en login: %v", err)
+			responses.Error(w, http.StatusInternalServerError, "Error al iniciar sesión")
+			return
+		}
 		responses.Error(w, http.StatusUnauthorized, "Email o contraseña incorrectos")
 		return
 	}
 
-	responses.JSON(w, http.StatusOK, map[string]string{
-		"token": token,
-	})
+	responses.JSON(w, http.StatusOK, tokenPairResponse(pair))
 }
 
 // MeHandler: Devuelve la identidad del usuario logueado con su nivel de acceso
@@ -125,7 +139,7 @@ func (h *AuthHandler) Verify(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.Service.Verify(r.Context(), req.Email, req.Code); err != nil {
-		responses.Error(w, http.StatusUnauthorized, "Código inválido")
+		verificationError(w, err)
 		return
 	}
 
@@ -176,7 +190,12 @@ func (h *AuthHandler) SaveProfessionalProfile(w http.ResponseWriter, r *http.Req
 	req.UserID = &uid
 
 	// 4. Ejecución en Base de Datos con Log de error detallado
-	if err := h.Service.UpsertProfessionalProfile(r.Context(), uid, &req); err != nil {
+	revision, err := h.Service.UpsertProfessionalProfile(r.Context(), uid, &req)
+	if errors.Is(err, domain.ErrProfileNameRequired) {
+		responses.Error(w, http.StatusBadRequest, "El nombre profesional es obligatorio")
+		return
+	}
+	if err != nil {
 		// Esto captura errores de PostgreSQL (como el Status 'ACTIVE' que falla)
 		fmt.Printf("❌ ERROR CRÍTICO DB UPSERT: %v\n", err)
 		responses.Error(w, http.StatusInternalServerError, err.Error())
@@ -186,6 +205,15 @@ func (h *AuthHandler) SaveProfessionalProfile(w http.ResponseWriter, r *http.Req
 	// NIVEL 3: Confirmación de éxito en terminal
 	fmt.Printf("✅ Perfil profesional guardado/actualizado para User: %s\n", uid)
 
+	// Los cambios en campos públicos esperan a moderación: se lo decimos al profesional
+	if revision != nil {
+		responses.JSON(w, http.StatusAccepted, map[string]interface{}{
+			"message":  "Cambios guardados. Los datos públicos se publicarán cuando los revisemos",
+			"revision": revision,
+		})
+		return
+	}
+
 	responses.JSON(w, http.StatusOK, map[string]string{
 		"message": "Perfil profesional actualizado con éxito",
 	})
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"veterimap-api/internal/auth"
+	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/responses"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ClaimHandler gestiona la reclamación de fichas importadas (PROSPECT)
+type ClaimHandler struct {
+	Service domain.ClaimService
+}
+
+func NewClaimHandler(service domain.ClaimService) *ClaimHandler {
+	return &ClaimHandler{Service: service}
+}
+
+// Request: El profesional pide hacerse con una ficha (POST /api/claims)
+func (h *ClaimHandler) Request(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		EntityID uuid.UUID          `json:"entity_id"`
+		Method   domain.ClaimMethod `json:"method"`
+		Evidence string             `json:"evidence"` // Obligatorio con method ADMIN
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.EntityID == uuid.Nil {
+		responses.Error(w, http.StatusBadRequest, "Datos inválidos: entity_id y method son obligatorios")
+		return
+	}
+	req.Method = domain.ClaimMethod(strings.ToUpper(string(req.Method)))
+	switch req.Method {
+	case domain.ClaimMethodEmail, domain.ClaimMethodPhone:
+	case domain.ClaimMethodAdmin:
+		if strings.TrimSpace(req.Evidence) == "" {
+			responses.Error(w, http.StatusBadRequest, "Describe cómo podemos comprobar que la ficha es tuya")
+			return
+		}
+	default:
+		responses.Error(w, http.StatusBadRequest, "method debe ser EMAIL, PHONE o ADMIN")
+		return
+	}
+
+	claim, err := h.Service.RequestClaim(r.Context(), userID, req.EntityID, req.Method, req.Evidence)
+	if err != nil {
+		claimError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusCreated, claim)
+}
+
+// Verify: Confirma la reclamación con el código recibido (POST /api/claims/{claimID}/verify)
+func (h *ClaimHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+	claimID, err := uuid.Parse(chi.URLParam(r, "claimID"))
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "ID de reclamación inválido")
+		return
+	}
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		responses.Error(w, http.StatusBadRequest, "Código requerido")
+		return
+	}
+
+	claim, err := h.Service.VerifyClaim(r.Context(), userID, claimID, req.Code)
+	if err != nil {
+		claimError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, claim)
+}
+
+// ListMine: Reclamaciones del profesional logueado (GET /api/claims)
+func (h *ClaimHandler) ListMine(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+
+	claims, err := h.Service.ListMyClaims(r.Context(), userID)
+	if err != nil {
+		claimError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, claims)
+}
+
+// AdminList: Cola de revisión (GET /api/admin/claims?status=PENDING)
+func (h *ClaimHandler) AdminList(w http.ResponseWriter, r *http.Request) {
+	status := domain.ClaimStatus(strings.ToUpper(r.URL.Query().Get("status")))
+
+	claims, err := h.Service.ListClaims(r.Context(), status)
+	if err != nil {
+		claimError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, claims)
+}
+
+// AdminApprove: POST /api/admin/claims/{claimID}/approve
+func (h *ClaimHandler) AdminApprove(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+	claimID, err := uuid.Parse(chi.URLParam(r, "claimID"))
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "ID de reclamación inválido")
+		return
+	}
+
+	claim, err := h.Service.ApproveClaim(r.Context(), adminID, claimID)
+	if err != nil {
+		claimError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, claim)
+}
+
+// AdminReject: POST /api/admin/claims/{claimID}/reject con {"note": "..."}
+func (h *ClaimHandler) AdminReject(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+	claimID, err := uuid.Parse(chi.URLParam(r, "claimID"))
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "ID de reclamación inválido")
+		return
+	}
+
+	var req struct {
+		Note string `json:"note"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req) // La nota es opcional
+
+	claim, err := h.Service.RejectClaim(r.Context(), adminID, claimID, req.Note)
+	if err != nil {
+		claimError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, claim)
+}
+
+// userIDFromClaims extrae el ID del usuario del JWT; si falla ya ha respondido
+func userIDFromClaims(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		responses.Error(w, http.StatusUnauthorized, "No autorizado")
+		return uuid.Nil, false
+	}
+	uid, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "Token corrupto")
+		return uuid.Nil, false
+	}
+	return uid, true
+}
+
+// claimError traduce los errores del dominio a códigos HTTP
+func claimError(w http.ResponseWriter, err error) {
+	var throttled *domain.ClaimThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		responses.Error(w, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, domain.ErrClaimNotFound), errors.Is(err, domain.ErrProfileNotFound):
+		responses.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrClaimNotAllowed), errors.Is(err, domain.ErrClaimNotPending):
+		responses.Error(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrClaimNoContact):
+		responses.Error(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, domain.ErrClaimCodeInvalid):
+		responses.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrClaimCodeExpired):
+		responses.Error(w, http.StatusGone, err.Error())
+	case errors.Is(err, domain.ErrClaimTooManyAttempts):
+		responses.Error(w, http.StatusTooManyRequests, err.Error())
+	default:
+		log.Printf("❌ Error en reclamación de ficha: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "Error al procesar la reclamación")
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+// trustedProxies son los proxies (TRUSTED_PROXIES) de los que se acepta X-Forwarded-For.
+// Vacío por defecto: sin proxy configurado cualquiera podría inventarse la cabecera.
+var trustedProxies []netip.Prefix
+
+// LoadTrustedProxiesFromEnv lee TRUSTED_PROXIES: IPs o rangos CIDR separados por
+// comas, p. ej. "10.0.0.0/8,127.0.0.1"
+func LoadTrustedProxiesFromEnv() error {
+	var prefixes []netip.Prefix
+	for _, item := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			addr, err := netip.ParseAddr(item)
+			if err != nil {
+				return fmt.Errorf("TRUSTED_PROXIES no válido: %q", item)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return fmt.Errorf("TRUSTED_PROXIES no válido: %q", item)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	trustedProxies = prefixes
+	return nil
+}
+
+func isTrustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range trustedProxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP devuelve la IP del cliente. X-Forwarded-For solo cuenta si la conexión
+// viene de un proxy de confianza, y se recorre de derecha a izquierda saltando los
+// proxies conocidos: lo que haya a la izquierda lo puede haber escrito el cliente.
+func clientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remote = host
+	}
+	if !isTrustedProxy(remote) {
+		return remote
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if _, err := netip.ParseAddr(hop); err != nil {
+			// Entrada mal formada: no se puede seguir confiando en lo que queda a la izquierda
+			break
+		}
+		if !isTrustedProxy(hop) {
+			return hop
+		}
+	}
+	return remote
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/responses"
+
+	"github.com/google/uuid"
+)
+
+// DuplicateHandler expone la cola de fichas duplicadas y la fusión (solo ADMIN)
+type DuplicateHandler struct {
+	Service domain.DuplicateService
+}
+
+func NewDuplicateHandler(service domain.DuplicateService) *DuplicateHandler {
+	return &DuplicateHandler{Service: service}
+}
+
+// List: GET /api/admin/duplicates?min_score=0.5&limit=20&offset=0 (más probables primero)
+func (h *DuplicateHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	minScore := domain.DuplicateDefaultScore
+	if raw := q.Get("min_score"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 || v > 1 {
+			responses.Error(w, http.StatusBadRequest, "min_score debe estar entre 0 y 1")
+			return
+		}
+		minScore = v
+	}
+	limit, offset := limitOffsetParams(q, adminDefaultLimit, adminMaxLimit)
+
+	total, candidates, err := h.Service.ListDuplicates(r.Context(), minScore, limit, offset)
+	if err != nil {
+		duplicateError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, map[string]interface{}{
+		"total":      total,
+		"candidates": candidates,
+	})
+}
+
+// Dismiss: POST /api/admin/duplicates/dismiss con {"a": "...", "b": "..."}
+func (h *DuplicateHandler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		A uuid.UUID `json:"a"`
+		B uuid.UUID `json:"b"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.A == uuid.Nil || req.B == uuid.Nil {
+		responses.Error(w, http.StatusBadRequest, "Datos inválidos: a y b son obligatorios")
+		return
+	}
+
+	if err := h.Service.Dismiss(r.Context(), adminID, req.A, req.B); err != nil {
+		duplicateError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, map[string]string{
+		"message": "Pareja descartada",
+	})
+}
+
+// Merge: POST /api/admin/duplicates/merge con {"survivor_id": "...", "merged_id": "..."}
+func (h *DuplicateHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		SurvivorID uuid.UUID `json:"survivor_id"`
+		MergedID   uuid.UUID `json:"merged_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.SurvivorID == uuid.Nil || req.MergedID == uuid.Nil {
+		responses.Error(w, http.StatusBadRequest, "Datos inválidos: survivor_id y merged_id son obligatorios")
+		return
+	}
+
+	merge, err := h.Service.Merge(r.Context(), adminID, req.SurvivorID, req.MergedID)
+	if err != nil {
+		duplicateError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, merge)
+}
+
+// duplicateError traduce los errores del dominio a códigos HTTP
+func duplicateError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrProfileNotFound):
+		responses.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrMergeSameEntity):
+		responses.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrMergeBothOwned):
+		responses.Error(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("❌ Error en duplicados: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "Error al procesar la petición")
+	}
+}
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"net/http"
+
+	"veterimap-api/internal/auth"
+	"veterimap-api/internal/pkg/responses"
+)
+
+// JWKS publica las claves públicas con las que se pueden validar los tokens de
+// Veterimap. Caché corta: tras una rotación, la clave nueva se ve en minutos.
+func JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	responses.JSON(w, http.StatusOK, auth.JWKS())
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/responses"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	revisionsDefaultLimit = 20
+	revisionsMaxLimit     = 100
+)
+
+// ModerationHandler es la cola de revisión de cambios en fichas (solo ADMIN)
+type ModerationHandler struct {
+	Service domain.ModerationService
+}
+
+func NewModerationHandler(service domain.ModerationService) *ModerationHandler {
+	return &ModerationHandler{Service: service}
+}
+
+// List: GET /api/admin/revisions?status=PENDING&limit=20&offset=0 (las marcadas primero)
+func (h *ModerationHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	status := domain.RevisionStatus(strings.ToUpper(q.Get("status")))
+	limit, offset := limitOffsetParams(q, revisionsDefaultLimit, revisionsMaxLimit)
+
+	total, revisions, err := h.Service.ListRevisions(r.Context(), status, limit, offset)
+	if err != nil {
+		revisionError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, map[string]interface{}{
+		"total":     total,
+		"revisions": revisions,
+	})
+}
+
+// Get: GET /api/admin/revisions/{revisionID}, con la versión publicada en "current"
+func (h *ModerationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := revisionIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	rev, err := h.Service.GetRevision(r.Context(), id)
+	if err != nil {
+		revisionError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, rev)
+}
+
+// Approve: POST /api/admin/revisions/{revisionID}/approve
+func (h *ModerationHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+	id, ok := revisionIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	rev, err := h.Service.ApproveRevision(r.Context(), adminID, id)
+	if err != nil {
+		revisionError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, rev)
+}
+
+// Reject: POST /api/admin/revisions/{revisionID}/reject con {"reason": "..."}
+func (h *ModerationHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+	id, ok := revisionIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		responses.Error(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	rev, err := h.Service.RejectRevision(r.Context(), adminID, id, req.Reason)
+	if err != nil {
+		revisionError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, rev)
+}
+
+func revisionIDParam(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "revisionID"))
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "ID de revisión inválido")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// revisionError traduce los errores del dominio a códigos HTTP
+func revisionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrRevisionNotFound):
+		responses.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrRevisionNotPending):
+		responses.Error(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrRevisionNoReason):
+		responses.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrProfileNameRequired):
+		// Se rechaza con motivo; aprobarla dejaría la ficha sin nombre
+		responses.Error(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		log.Printf("❌ Error en moderación de fichas: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "Error al procesar la revisión")
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"veterimap-api/internal/auth"
+	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/responses"
+
+	"github.com/google/uuid"
+)
+
+// PasswordHandler gestiona la recuperación y el cambio de contraseña
+type PasswordHandler struct {
+	Service domain.PasswordService
+}
+
+func NewPasswordHandler(service domain.PasswordService) *PasswordHandler {
+	return &PasswordHandler{Service: service}
+}
+
+// Forgot: POST /api/auth/password/forgot {email}. Responde igual exista o no la cuenta.
+func (h *PasswordHandler) Forgot(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		responses.Error(w, http.StatusBadRequest, "Indica el email de la cuenta")
+		return
+	}
+
+	if err := h.Service.RequestReset(r.Context(), req.Email, sessionMeta(r)); err != nil {
+		passwordError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusAccepted, map[string]string{
+		"message": "Si el email está registrado, recibirás un enlace para restablecer la contraseña.",
+	})
+}
+
+// Reset: POST /api/auth/password/reset {token, password}
+func (h *PasswordHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		responses.Error(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	if err := h.Service.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		passwordError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Change: POST /api/users/me/password {current_password, new_password}
+func (h *PasswordHandler) Change(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		responses.Error(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+	claims, _ := auth.GetClaims(r.Context())
+	sessionID, _ := uuid.Parse(claims.SessionID)
+
+	if err := h.Service.ChangePassword(r.Context(), userID, sessionID, req.CurrentPassword, req.NewPassword); err != nil {
+		passwordError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// passwordError traduce los errores del dominio a códigos HTTP
+func passwordError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrResetTokenInvalid):
+		responses.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrWrongPassword):
+		responses.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, domain.ErrWeakPassword):
+		responses.Error(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, domain.ErrUserNotFound):
+		responses.Error(w, http.StatusNotFound, err.Error())
+	default:
+		log.Printf("❌ Error en la gestión de contraseñas: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "Error al procesar la contraseña")
+	}
+}
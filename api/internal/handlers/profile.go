@@ -1,11 +1,53 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/geo"
+	"veterimap-api/internal/pkg/mvt"
 	"veterimap-api/internal/pkg/responses"
+	"veterimap-api/internal/pkg/slug"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	mapMaxResults     = 1000 // Tope de pines por petición al mapa
+	defaultRadiusKm   = 10.0 // Radio si llega lat/lng sin radius_km
+	maxSearchRadiusKm = 300.0
+
+	// Por debajo de este zoom el mapa recibe clusters en lugar de pines
+	clusterMaxZoom  = 12
+	clusterRadiusPx = 60
+	maxClusters     = 500
+
+	// Teselas vectoriales
+	tileLayerName   = "professionals"
+	tileBuffer      = 0.0625 // 1/16 de tesela alrededor para no cortar iconos en los bordes
+	maxTileFeatures = 20000
+	tileCacheMaxAge = 300 // segundos
+
+	// Autocompletado: se dispara en cada pulsación, así que tiene un presupuesto de tiempo corto
+	suggestMinChars     = 2
+	suggestDefaultLimit = 5
+	suggestMaxLimit     = 10
+	suggestTimeout      = 300 * time.Millisecond
+
+	// Listado del marketplace
+	listDefaultLimit = 10
+	listMaxLimit     = 50
+
+	formatJSON    = "json"
+	formatGeoJSON = "geojson"
 )
 
 // ProfileHandler gestiona la búsqueda pública y el mapa
@@ -19,68 +61,334 @@ func NewProfileHandler(repo domain.ProfileRepository) *ProfileHandler {
 
 // List: Lista paginada para el Marketplace
 func (h *ProfileHandler) List(w http.ResponseWriter, r *http.Request) {
-	name := r.URL.Query().Get("name")
+	// q es la búsqueda de texto completo; name se mantiene por compatibilidad con el frontend
+	text := r.URL.Query().Get("q")
+	if text == "" {
+		text = r.URL.Query().Get("name")
+	}
 	city := r.URL.Query().Get("city")
 	tag := r.URL.Query().Get("tag")
 
-	limit := 10
+	format, err := parseFormat(r)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit := listDefaultLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > listMaxLimit {
+		limit = listMaxLimit
+	}
+
+	// page se mantiene para el paginado clásico; el scroll infinito debe usar cursor
 	page := 1
 	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
 		page = p
 	}
 	offset := (page - 1) * limit
 
-	total, profiles, err := h.Repo.SearchProfiles(r.Context(), name, city, tag, limit, offset)
+	filter := domain.ProfileFilter{
+		Query:  text,
+		City:   city,
+		Tag:    tag,
+		Cursor: r.URL.Query().Get("cursor"),
+		Limit:  limit,
+		Offset: offset,
+	}
+	// lat/lng o bbox permiten ordenar por distancia
+	if err := parseGeoParams(r, &filter); err != nil {
+		responses.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := parseOpenParams(r, &filter); err != nil {
+		responses.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := parseFacetParams(r, &filter); err != nil {
+		responses.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := parseSortParam(r, &filter); err != nil {
+		responses.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.Repo.SearchProfiles(r.Context(), filter)
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		responses.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		responses.Error(w, http.StatusInternalServerError, "Error al buscar: "+err.Error())
 		return
 	}
+	total, profiles := result.Total, result.Profiles
+	if profiles == nil {
+		profiles = []domain.ProfileSummary{}
+	}
+
+	if format == formatGeoJSON {
+		// La paginación viaja en cabeceras para que el cuerpo sea un FeatureCollection puro
+		w.Header().Set("X-Total-Count", strconv.Itoa(total))
+		if result.NextCursor != "" {
+			w.Header().Set("X-Next-Cursor", result.NextCursor)
+		}
+		responses.GeoJSON(w, http.StatusOK, profilesToGeoJSON(profiles))
+		return
+	}
+
+	// Contadores del panel lateral, calculados sobre los mismos filtros
+	facets, err := h.Repo.FacetProfiles(r.Context(), filter)
+	if err != nil {
+		responses.Error(w, http.StatusInternalServerError, "Error al calcular filtros: "+err.Error())
+		return
+	}
 
 	responses.JSON(w, http.StatusOK, map[string]interface{}{
-		"total":    total,
-		"page":     page,
-		"limit":    limit,
-		"profiles": profiles,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+		"next_cursor": result.NextCursor,
+		"profiles":    profiles,
+		"facets":      facets,
 	})
 }
 
-// SearchMap: Procesa la búsqueda para los pines del mapa (Público)
+// SearchMap: Procesa la búsqueda para los pines del mapa (Público).
+// Con ?zoom= por debajo de clusterMaxZoom devuelve clusters en lugar de pines.
 func (h *ProfileHandler) SearchMap(w http.ResponseWriter, r *http.Request) {
 	city := r.URL.Query().Get("city")
-	entityType := r.URL.Query().Get("type")
+	entityType := entityTypeParam(r.URL.Query().Get("type"))
 	specialty := r.URL.Query().Get("specialty")
 
-	// TRADUCCIÓN DE NOMENCLATURA (Frontend -> DB)
-	switch entityType {
-	case "fichas_clinicas":
-		entityType = "CLINIC"
-	case "fichas_hospitales":
-		entityType = "HOSPITAL"
-	case "fichas_veterinarios":
-		entityType = "HOME_VET"
-	}
-
 	tagABuscar := entityType
 	if entityType == "INDIVIDUAL" && specialty != "" {
 		tagABuscar = specialty
 	}
 
-	_, results, err := h.Repo.SearchProfiles(r.Context(), "", city, tagABuscar, 1000, 0)
+	format, err := parseFormat(r)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Un pin por ubicación: las cadenas y los veterinarios a domicilio salen en todas sus direcciones
+	filter := domain.ProfileFilter{
+		City:        city,
+		Tag:         tagABuscar,
+		PerLocation: true,
+		Limit:       mapMaxResults,
+	}
+
+	// Viewport (bbox) o centro + radio. Sin ellos se mantiene la búsqueda por ciudad.
+	if err := parseGeoParams(r, &filter); err != nil {
+		responses.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := parseOpenParams(r, &filter); err != nil {
+		responses.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Zoom alejado: agrupamos en servidor para que el payload no dependa del nº de clínicas
+	if zs := r.URL.Query().Get("zoom"); zs != "" {
+		zoom, err := strconv.Atoi(zs)
+		if err != nil || zoom < 0 || zoom > 22 {
+			responses.Error(w, http.StatusBadRequest, "zoom debe ser un entero entre 0 y 22")
+			return
+		}
+
+		if zoom < clusterMaxZoom {
+			filter.Limit = maxClusters
+			clusters, err := h.Repo.ClusterProfiles(r.Context(), filter, geo.ClusterCellSize(zoom, clusterRadiusPx))
+			if err != nil {
+				log.Printf("ERROR EN CLUSTERS: %v", err)
+				responses.Error(w, http.StatusInternalServerError, "Error en la búsqueda del mapa")
+				return
+			}
+			if clusters == nil {
+				clusters = []domain.ProfileCluster{}
+			}
+
+			if format == formatGeoJSON {
+				responses.GeoJSON(w, http.StatusOK, clustersToGeoJSON(clusters))
+				return
+			}
+
+			responses.JSON(w, http.StatusOK, map[string]interface{}{
+				"mode":     "clusters",
+				"zoom":     zoom,
+				"clusters": clusters,
+			})
+			return
+		}
+	}
+
+	page, err := h.Repo.SearchProfiles(r.Context(), filter)
 	if err != nil {
 		log.Printf("ERROR EN MAPA: %v", err)
 		responses.Error(w, http.StatusInternalServerError, "Error en la búsqueda del mapa")
 		return
 	}
+	results := page.Profiles
 
 	if results == nil {
 		results = []domain.ProfileSummary{}
 	}
 
+	if format == formatGeoJSON {
+		responses.GeoJSON(w, http.StatusOK, profilesToGeoJSON(results))
+		return
+	}
+
 	responses.JSON(w, http.StatusOK, map[string]interface{}{
+		"mode":    "pins",
 		"results": results,
 	})
 }
 
+// Suggest: Autocompletado del buscador (?q=). Devuelve clínicas, ciudades y especialidades
+// mezcladas. Si se agota el presupuesto de tiempo responde vacío en lugar de error:
+// el usuario seguirá tecleando y la siguiente petición llegará enseguida.
+func (h *ProfileHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	prefix := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	limit := suggestDefaultLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > suggestMaxLimit {
+		limit = suggestMaxLimit
+	}
+
+	suggestions := []domain.Suggestion{}
+	if len([]rune(prefix)) >= suggestMinChars {
+		ctx, cancel := context.WithTimeout(r.Context(), suggestTimeout)
+		defer cancel()
+
+		results, err := h.Repo.Suggest(ctx, prefix, limit)
+		if err != nil {
+			log.Printf("⚠️ Sugerencias para %q sin respuesta: %v", prefix, err)
+		} else if results != nil {
+			suggestions = results
+		}
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	responses.JSON(w, http.StatusOK, map[string]interface{}{
+		"query":       prefix,
+		"suggestions": suggestions,
+	})
+}
+
+// GetBySlug: Ficha pública en /api/profiles/{slug}. Los slugs antiguos (cambio de
+// nombre) o escritos sin normalizar responden 301 a la URL canónica.
+func (h *ProfileHandler) GetBySlug(w http.ResponseWriter, r *http.Request) {
+	requested := chi.URLParam(r, "slug")
+	canonical := slug.Make(requested)
+	if canonical == "" {
+		responses.Error(w, http.StatusNotFound, "Perfil no encontrado")
+		return
+	}
+
+	id, current, err := h.Repo.ResolveSlug(r.Context(), canonical)
+	if errors.Is(err, domain.ErrProfileNotFound) {
+		responses.Error(w, http.StatusNotFound, "Perfil no encontrado")
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Error resolviendo slug [%s]: %v", requested, err)
+		responses.Error(w, http.StatusInternalServerError, "Error al cargar el perfil")
+		return
+	}
+
+	if current != requested {
+		target := "/api/profiles/" + current
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+
+	detail, err := h.Repo.GetProfileDetail(r.Context(), id)
+	if err != nil {
+		log.Printf("❌ Error cargando perfil [%s]: %v", current, err)
+		responses.Error(w, http.StatusNotFound, "Perfil no encontrado")
+		return
+	}
+	responses.JSON(w, http.StatusOK, detail)
+}
+
+// Tile: Sirve /tiles/{z}/{x}/{y}.mvt con las entidades activas como Mapbox Vector Tile.
+// Las teselas son cacheables (Cache-Control + ETag), así el mapa no repite búsquedas al desplazarse.
+func (h *ProfileHandler) Tile(w http.ResponseWriter, r *http.Request) {
+	z, errZ := strconv.Atoi(chi.URLParam(r, "z"))
+	x, errX := strconv.Atoi(chi.URLParam(r, "x"))
+	y, errY := strconv.Atoi(chi.URLParam(r, "y"))
+	if errZ != nil || errX != nil || errY != nil || !geo.ValidTile(z, x, y) {
+		responses.Error(w, http.StatusBadRequest, "Tesela inválida")
+		return
+	}
+
+	swLat, swLng, neLat, neLng := geo.TileBounds(z, x, y, tileBuffer)
+	filter := domain.ProfileFilter{
+		Tag: entityTypeParam(r.URL.Query().Get("type")),
+		Bounds: &domain.GeoBounds{
+			SouthWest: domain.GeoPoint{Lat: swLat, Lng: swLng},
+			NorthEast: domain.GeoPoint{Lat: neLat, Lng: neLng},
+		},
+		Limit: maxTileFeatures,
+	}
+
+	profiles, err := h.Repo.TileProfiles(r.Context(), filter)
+	if err != nil {
+		log.Printf("ERROR EN TESELA %d/%d/%d: %v", z, x, y, err)
+		responses.Error(w, http.StatusInternalServerError, "Error generando la tesela")
+		return
+	}
+
+	layer := mvt.Layer{Name: tileLayerName, Extent: mvt.DefaultExtent}
+	for _, p := range profiles {
+		px, py := geo.ProjectToTile(p.Latitude, p.Longitude, z, x, y, mvt.DefaultExtent)
+		layer.Features = append(layer.Features, mvt.Feature{
+			X: px,
+			Y: py,
+			Properties: map[string]interface{}{
+				"id":            p.ID,
+				"name":          p.Name,
+				"entity_type":   p.ProfileType,
+				"rating":        p.Rating,
+				"review_count":  p.ReviewCount,
+				"address_index": p.AddressIndex,
+			},
+		})
+	}
+
+	tile, err := mvt.Encode(layer)
+	if err != nil {
+		log.Printf("ERROR CODIFICANDO TESELA %d/%d/%d: %v", z, x, y, err)
+		responses.Error(w, http.StatusInternalServerError, "Error generando la tesela")
+		return
+	}
+
+	sum := sha1.Sum(tile)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", tileCacheMaxAge))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.mapbox-vector-tile")
+	w.WriteHeader(http.StatusOK)
+	w.Write(tile)
+}
+
 // GetDetail: Ficha completa de una veterinaria con diagnóstico
 func (h *ProfileHandler) GetDetail(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
@@ -106,3 +414,192 @@ func (h *ProfileHandler) GetDetail(w http.ResponseWriter, r *http.Request) {
 	log.Printf("✅ ÉXITO: Perfil [%s] cargado correctamente", detail.Name)
 	responses.JSON(w, http.StatusOK, detail)
 }
+
+// parseGeoParams lee ?bbox=sw_lat,sw_lng,ne_lat,ne_lng o ?lat=&lng=&radius_km= y los vuelca al filtro
+func parseGeoParams(r *http.Request, f *domain.ProfileFilter) error {
+	query := r.URL.Query()
+
+	if bbox := query.Get("bbox"); bbox != "" {
+		parts := strings.Split(bbox, ",")
+		if len(parts) != 4 {
+			return errors.New("bbox debe ser sw_lat,sw_lng,ne_lat,ne_lng")
+		}
+		var coords [4]float64
+		for i, part := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return fmt.Errorf("bbox contiene un valor no numérico: %q", part)
+			}
+			coords[i] = v
+		}
+		sw := domain.GeoPoint{Lat: coords[0], Lng: coords[1]}
+		ne := domain.GeoPoint{Lat: coords[2], Lng: coords[3]}
+		if !geo.ValidCoordinate(sw.Lat, sw.Lng) || !geo.ValidCoordinate(ne.Lat, ne.Lng) || sw.Lat > ne.Lat || sw.Lng > ne.Lng {
+			return errors.New("bbox fuera de rango o con esquinas invertidas")
+		}
+		f.Bounds = &domain.GeoBounds{SouthWest: sw, NorthEast: ne}
+	}
+
+	latStr, lngStr := query.Get("lat"), query.Get("lng")
+	if latStr == "" && lngStr == "" {
+		return nil
+	}
+
+	lat, errLat := strconv.ParseFloat(latStr, 64)
+	lng, errLng := strconv.ParseFloat(lngStr, 64)
+	if errLat != nil || errLng != nil || !geo.ValidCoordinate(lat, lng) {
+		return errors.New("lat y lng deben ser coordenadas válidas")
+	}
+
+	radius := defaultRadiusKm
+	if rs := query.Get("radius_km"); rs != "" {
+		v, err := strconv.ParseFloat(rs, 64)
+		if err != nil || v <= 0 {
+			return errors.New("radius_km debe ser un número positivo")
+		}
+		radius = v
+	}
+	if radius > maxSearchRadiusKm {
+		radius = maxSearchRadiusKm
+	}
+
+	f.Center = &domain.GeoPoint{Lat: lat, Lng: lng}
+	f.RadiusKm = radius
+	return nil
+}
+
+// parseOpenParams lee ?open_now=true u ?open_at=<RFC3339> (se evalúa en hora de Madrid)
+func parseOpenParams(r *http.Request, f *domain.ProfileFilter) error {
+	if openAt := r.URL.Query().Get("open_at"); openAt != "" {
+		t, err := time.Parse(time.RFC3339, openAt)
+		if err != nil {
+			return errors.New("open_at debe tener formato RFC3339 (2025-01-31T18:30:00+01:00)")
+		}
+		f.OpenAt = &t
+		return nil
+	}
+
+	if openNow, _ := strconv.ParseBool(r.URL.Query().Get("open_now")); openNow {
+		now := time.Now()
+		f.OpenAt = &now
+	}
+	return nil
+}
+
+// entityTypeParam traduce la nomenclatura del frontend (fichas_*) a entity_type
+func entityTypeParam(v string) string {
+	switch v {
+	case "fichas_clinicas":
+		return "CLINIC"
+	case "fichas_hospitales":
+		return "HOSPITAL"
+	case "fichas_veterinarios":
+		return "HOME_VET"
+	}
+	return v
+}
+
+// parseFacetParams lee los filtros del panel lateral: type, specialty, min_rating e insurance
+func parseFacetParams(r *http.Request, f *domain.ProfileFilter) error {
+	q := r.URL.Query()
+	f.EntityType = entityTypeParam(q.Get("type"))
+	f.Specialty = q.Get("specialty")
+
+	if v := q.Get("min_rating"); v != "" {
+		rating, err := strconv.ParseFloat(v, 64)
+		if err != nil || rating < 0 || rating > 5 {
+			return errors.New("min_rating debe ser un número entre 0 y 5")
+		}
+		f.MinRating = rating
+	}
+	if v := q.Get("insurance"); v != "" {
+		accepts, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.New("insurance debe ser true o false")
+		}
+		f.AcceptsInsurance = accepts
+	}
+	return nil
+}
+
+// parseSortParam valida ?sort= y que la ordenación tenga sentido con el filtro recibido
+func parseSortParam(r *http.Request, f *domain.ProfileFilter) error {
+	sort := r.URL.Query().Get("sort")
+	switch sort {
+	case "":
+	case domain.ProfileSortRelevance:
+		if strings.TrimSpace(f.Query) == "" {
+			return errors.New("sort=relevance requiere el parámetro q")
+		}
+	case domain.ProfileSortDistance:
+		if f.Center == nil && f.Bounds == nil {
+			return errors.New("sort=distance requiere lat/lng o bbox")
+		}
+	case domain.ProfileSortRating, domain.ProfileSortReviews, domain.ProfileSortName, domain.ProfileSortNewest:
+	default:
+		return errors.New("sort debe ser relevance, distance, rating, reviews, name o newest")
+	}
+	f.Sort = sort
+	return nil
+}
+
+// parseFormat valida ?format= (json por defecto o geojson)
+func parseFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", formatJSON:
+		return formatJSON, nil
+	case formatGeoJSON:
+		return formatGeoJSON, nil
+	default:
+		return "", fmt.Errorf("formato no soportado: %q (usa json o geojson)", format)
+	}
+}
+
+// profilesToGeoJSON convierte los resúmenes en puntos. Los perfiles sin coordenadas
+// (0,0) se omiten: en GeoJSON acabarían en mitad del Atlántico.
+func profilesToGeoJSON(profiles []domain.ProfileSummary) geo.FeatureCollection {
+	fc := geo.NewFeatureCollection()
+	for _, p := range profiles {
+		if p.Latitude == 0 && p.Longitude == 0 {
+			continue
+		}
+		props := map[string]interface{}{
+			"name":          p.Name,
+			"entity_type":   p.ProfileType,
+			"rating":        p.Rating,
+			"review_count":  p.ReviewCount,
+			"city":          p.City,
+			"full_address":  p.FullAddress,
+			"address_index": p.AddressIndex,
+			"is_main":       p.IsMain,
+		}
+		if p.DistanceKm != nil {
+			props["distance_km"] = *p.DistanceKm
+		}
+		if p.IsOpenNow != nil {
+			props["is_open_now"] = *p.IsOpenNow
+		}
+		if p.NextOpening != nil {
+			props["next_opening"] = p.NextOpening.Format(time.RFC3339)
+		}
+		fc.Features = append(fc.Features, geo.NewPointFeature(p.ID, p.Latitude, p.Longitude, props))
+	}
+	return fc
+}
+
+// clustersToGeoJSON sigue la convención de Mapbox/Supercluster (cluster, point_count)
+func clustersToGeoJSON(clusters []domain.ProfileCluster) geo.FeatureCollection {
+	fc := geo.NewFeatureCollection()
+	for _, c := range clusters {
+		fc.Features = append(fc.Features, geo.NewPointFeature("", c.Latitude, c.Longitude, map[string]interface{}{
+			"cluster":     true,
+			"point_count": c.Count,
+			"entity_type": c.EntityType,
+			"bbox": []float64{
+				c.Bounds.SouthWest.Lng, c.Bounds.SouthWest.Lat,
+				c.Bounds.NorthEast.Lng, c.Bounds.NorthEast.Lat,
+			},
+		}))
+	}
+	return fc
+}
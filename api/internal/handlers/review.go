@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/responses"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	reviewsDefaultLimit = 10
+	reviewsMaxLimit     = 50
+)
+
+// ReviewHandler gestiona las reseñas de las fichas profesionales
+type ReviewHandler struct {
+	Service domain.ReviewService
+}
+
+func NewReviewHandler(service domain.ReviewService) *ReviewHandler {
+	return &ReviewHandler{Service: service}
+}
+
+// List: Reseñas publicadas de una ficha (GET /api/profiles/{slug}/reviews?page=&limit=).
+// {slug} admite también el id de la ficha.
+func (h *ReviewHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit := reviewsDefaultLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > reviewsMaxLimit {
+		limit = reviewsMaxLimit
+	}
+	page := 1
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		page = p
+	}
+
+	total, reviews, err := h.Service.ListReviews(r.Context(), chi.URLParam(r, "slug"), limit, (page-1)*limit)
+	if err != nil {
+		reviewError(w, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, map[string]interface{}{
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+		"reviews": reviews,
+	})
+}
+
+// Create: El dueño valora una cita completada (POST /api/reviews)
+func (h *ReviewHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		AppointmentID uuid.UUID `json:"appointment_id"`
+		Stars         int       `json:"stars"`
+		Body          string    `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.AppointmentID == uuid.Nil {
+		responses.Error(w, http.StatusBadRequest, "Datos inválidos: appointment_id y stars son obligatorios")
+		return
+	}
+
+	review, err := h.Service.CreateReview(r.Context(), userID, req.AppointmentID, req.Stars, req.Body)
+	if err != nil {
+		reviewError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusCreated, review)
+}
+
+// Reply: Respuesta pública del titular de la ficha (POST /api/reviews/{reviewID}/reply)
+func (h *ReviewHandler) Reply(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+	reviewID, err := uuid.Parse(chi.URLParam(r, "reviewID"))
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "ID de reseña inválido")
+		return
+	}
+
+	var req struct {
+		Reply string `json:"reply"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		responses.Error(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	review, err := h.Service.Reply(r.Context(), userID, reviewID, req.Reply)
+	if err != nil {
+		reviewError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, review)
+}
+
+// Report: Denuncia una reseña abusiva (POST /api/reviews/{reviewID}/report)
+func (h *ReviewHandler) Report(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+	reviewID, err := uuid.Parse(chi.URLParam(r, "reviewID"))
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "ID de reseña inválido")
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		responses.Error(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	if err := h.Service.Report(r.Context(), userID, reviewID, req.Reason); err != nil {
+		reviewError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusAccepted, map[string]string{
+		"message": "Gracias, revisaremos la reseña",
+	})
+}
+
+// AdminList: GET /api/admin/reviews?status=HIDDEN&limit=20&offset=0. Por defecto las
+// ocultas, con las que nadie ha revisado (ocultas por reportes) primero.
+func (h *ReviewHandler) AdminList(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	status := domain.ReviewStatus(strings.ToUpper(q.Get("status")))
+	limit, offset := limitOffsetParams(q, reviewsDefaultLimit, reviewsMaxLimit)
+
+	total, reviews, err := h.Service.ListModeration(r.Context(), status, limit, offset)
+	if err != nil {
+		reviewError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, map[string]interface{}{
+		"total":   total,
+		"reviews": reviews,
+	})
+}
+
+// AdminRestore: POST /api/admin/reviews/{reviewID}/restore. Vuelve a publicarla y
+// los reportes posteriores ya no la ocultan.
+func (h *ReviewHandler) AdminRestore(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, h.Service.Restore)
+}
+
+// AdminHide: POST /api/admin/reviews/{reviewID}/hide. Confirma la ocultación.
+func (h *ReviewHandler) AdminHide(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, h.Service.Hide)
+}
+
+func (h *ReviewHandler) moderate(w http.ResponseWriter, r *http.Request, decide func(ctx context.Context, adminID, reviewID uuid.UUID) (*domain.Review, error)) {
+	adminID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+	reviewID, err := uuid.Parse(chi.URLParam(r, "reviewID"))
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, "ID de reseña inválido")
+		return
+	}
+
+	review, err := decide(r.Context(), adminID, reviewID)
+	if err != nil {
+		reviewError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, review)
+}
+
+// reviewError traduce los errores del dominio a códigos HTTP
+func reviewError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrReviewInvalid), errors.Is(err, domain.ErrReviewStatusInvalid):
+		responses.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrReviewNotFound), errors.Is(err, domain.ErrProfileNotFound):
+		responses.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrReviewNotAllowed):
+		responses.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, domain.ErrReviewDuplicate), errors.Is(err, domain.ErrReviewAlreadyReplied),
+		errors.Is(err, domain.ErrReviewAlreadyReport):
+		responses.Error(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("❌ Error en reseñas: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "Error al procesar la reseña")
+	}
+}
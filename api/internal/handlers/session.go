@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"veterimap-api/internal/auth"
+	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/responses"
+
+	"github.com/google/uuid"
+)
+
+// SessionHandler renueva tokens y gestiona los dispositivos con sesión iniciada
+type SessionHandler struct {
+	Service domain.SessionService
+}
+
+func NewSessionHandler(service domain.SessionService) *SessionHandler {
+	return &SessionHandler{Service: service}
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Refresh: POST /api/auth/refresh {refresh_token} → par nuevo; el anterior deja de valer
+func (h *SessionHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		responses.Error(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	pair, err := h.Service.Refresh(r.Context(), req.RefreshToken, sessionMeta(r))
+	if err != nil {
+		sessionError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, tokenPairResponse(pair))
+}
+
+// Logout: POST /api/auth/logout {refresh_token} cierra la sesión de este dispositivo
+func (h *SessionHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		responses.Error(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	if err := h.Service.Logout(r.Context(), req.RefreshToken); err != nil {
+		sessionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutAll: POST /api/auth/logout-all cierra la sesión en todos los dispositivos
+func (h *SessionHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.Service.LogoutAll(r.Context(), userID)
+	if err != nil {
+		sessionError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, map[string]int{"revoked": revoked})
+}
+
+// List: GET /api/users/me/sessions, marcando con "current" la del token actual
+func (h *SessionHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+	claims, _ := auth.GetClaims(r.Context())
+	current, _ := uuid.Parse(claims.SessionID)
+
+	sessions, err := h.Service.ListSessions(r.Context(), userID, current)
+	if err != nil {
+		sessionError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusOK, map[string]interface{}{"sessions": sessions})
+}
+
+// Revoke: DELETE /api/users/me/sessions/{sessionID}
+func (h *SessionHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDFromClaims(w, r)
+	if !ok {
+		return
+	}
+	sessionID, ok := uuidParam(w, r, "sessionID")
+	if !ok {
+		return
+	}
+
+	if err := h.Service.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		sessionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tokenPairResponse mantiene "token" para los clientes que solo conocen el access token
+func tokenPairResponse(pair *domain.TokenPair) map[string]interface{} {
+	return map[string]interface{}{
+		"token":         pair.AccessToken,
+		"access_token":  pair.AccessToken,
+		"refresh_token": pair.RefreshToken,
+		"expires_in":    pair.ExpiresIn,
+		"session_id":    pair.SessionID,
+	}
+}
+
+// sessionMeta identifica el dispositivo. La IP sale de clientIP: X-Forwarded-For
+// solo se tiene en cuenta detrás de un proxy de TRUSTED_PROXIES.
+func sessionMeta(r *http.Request) domain.SessionMeta {
+	return domain.SessionMeta{UserAgent: r.UserAgent(), IP: clientIP(r)}
+}
+
+// sessionError traduce los errores del dominio a códigos HTTP
+func sessionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrRefreshTokenInvalid), errors.Is(err, domain.ErrRefreshTokenReused):
+		responses.Error(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, domain.ErrSessionNotFound):
+		responses.Error(w, http.StatusNotFound, err.Error())
+	default:
+		log.Printf("❌ Error en sesiones: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "Error al procesar la sesión")
+	}
+}
@@ -16,12 +16,14 @@ import (
 type UserHandler struct {
 	UserRepo    domain.UserRepository
 	ProfileRepo domain.ProfileRepository
+	Mailer      domain.Mailer
 }
 
-func NewUserHandler(userRepo domain.UserRepository, profileRepo domain.ProfileRepository) *UserHandler {
+func NewUserHandler(userRepo domain.UserRepository, profileRepo domain.ProfileRepository, mailer domain.Mailer) *UserHandler {
 	return &UserHandler{
 		UserRepo:    userRepo,
 		ProfileRepo: profileRepo,
+		Mailer:      mailer,
 	}
 }
 
@@ -305,6 +307,9 @@ func (h *UserHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 		responses.Error(w, http.StatusInternalServerError, "Error al actualizar estado")
 		return
 	}
+	if input.Status == "CONFIRMED" {
+		h.notifyOwner(r, input.AppointmentID, domain.EmailAppointmentConfirmed)
+	}
 	responses.JSON(w, http.StatusOK, map[string]string{"message": "Estado actualizado"})
 }
 
@@ -330,10 +335,31 @@ func (h *UserHandler) Reschedule(w http.ResponseWriter, r *http.Request) {
 		responses.Error(w, http.StatusInternalServerError, "Error al guardar en base de datos")
 		return
 	}
+	h.notifyOwner(r, input.AppointmentID, domain.EmailAppointmentRescheduled)
 
 	responses.JSON(w, http.StatusOK, map[string]string{"message": "Reagendación exitosa"})
 }
 
+// notifyOwner avisa al dueño por correo de un cambio en su cita. Si falla, la
+// cita ya está guardada: solo se registra en el log.
+func (h *UserHandler) notifyOwner(r *http.Request, appID uuid.UUID, template string) {
+	n, err := h.UserRepo.GetAppointmentNotice(r.Context(), appID)
+	if err != nil {
+		log.Printf("❌ No se pudo cargar la cita %s para avisar al dueño: %v", appID, err)
+		return
+	}
+	err = h.Mailer.Send(r.Context(), n.OwnerEmail, n.OwnerLocale, template, map[string]interface{}{
+		"OwnerName":        n.OwnerName,
+		"PetName":          n.PetName,
+		"ProfessionalName": n.ProfessionalName,
+		"Date":             n.AppointmentDate.In(domain.ScheduleLocation()).Format("02/01/2006 15:04"),
+		"Notes":            n.Notes,
+	})
+	if err != nil {
+		log.Printf("❌ No se pudo avisar a %s de la cita %s: %v", n.OwnerEmail, appID, err)
+	}
+}
+
 func (h *UserHandler) GetMyClients(w http.ResponseWriter, r *http.Request) {
 	claims, _ := auth.GetClaims(r.Context())
 	userID, _ := uuid.Parse(claims.UserID)
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/responses"
+)
+
+// Códigos de error (response.code) de la verificación de cuenta
+const (
+	codeInvalidCode      = "INVALID_CODE"
+	codeCodeExpired      = "CODE_EXPIRED"
+	codeTooManyAttempts  = "TOO_MANY_ATTEMPTS"
+	codeAlreadyVerified  = "ALREADY_VERIFIED"
+	codeResendTooSoon    = "RESEND_TOO_SOON"
+	codeEmailNotVerified = "EMAIL_NOT_VERIFIED"
+)
+
+// ResendVerification: POST /api/auth/verify/resend {email}. Responde igual exista o no la cuenta.
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		responses.Error(w, http.StatusBadRequest, "Indica el email de la cuenta")
+		return
+	}
+
+	if err := h.Service.ResendVerification(r.Context(), req.Email); err != nil {
+		verificationError(w, err)
+		return
+	}
+	responses.JSON(w, http.StatusAccepted, map[string]string{
+		"message": "Si la cuenta está pendiente de verificar, recibirás un código nuevo.",
+	})
+}
+
+func verificationError(w http.ResponseWriter, err error) {
+	var throttled *domain.VerificationThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		seconds := int(math.Ceil(throttled.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		responses.ErrorCode(w, http.StatusTooManyRequests, codeResendTooSoon, err.Error())
+	case errors.Is(err, domain.ErrVerificationCodeInvalid):
+		responses.ErrorCode(w, http.StatusBadRequest, codeInvalidCode, err.Error())
+	case errors.Is(err, domain.ErrVerificationCodeExpired):
+		responses.ErrorCode(w, http.StatusGone, codeCodeExpired, err.Error())
+	case errors.Is(err, domain.ErrVerificationLocked):
+		responses.ErrorCode(w, http.StatusTooManyRequests, codeTooManyAttempts, err.Error())
+	case errors.Is(err, domain.ErrAlreadyVerified):
+		responses.ErrorCode(w, http.StatusConflict, codeAlreadyVerified, err.Error())
+	case errors.Is(err, domain.ErrEmailNotVerified):
+		responses.ErrorCode(w, http.StatusForbidden, codeEmailNotVerified, "Verifica tu email antes de iniciar sesión")
+	default:
+		log.Printf("❌ Error en la verificación de cuenta: %v", err)
+		responses.Error(w, http.StatusInternalServerError, "Error al verificar la cuenta")
+	}
+}
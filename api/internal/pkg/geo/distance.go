@@ -0,0 +1,46 @@
+package geo
+
+import "math"
+
+// EarthRadiusKm es el radio medio terrestre usado en todos los cálculos de distancia
+const EarthRadiusKm = 6371.0
+
+// HaversineKm devuelve la distancia en km entre dos coordenadas.
+// Es la misma fórmula que la función SQL haversine_km de las migraciones.
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+	a := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Pow(math.Sin(dLng/2), 2)
+	return EarthRadiusKm * 2 * math.Asin(math.Sqrt(a))
+}
+
+// BoundsAround calcula el rectángulo (swLat, swLng, neLat, neLng) que contiene
+// el círculo de radio radiusKm. Sirve de pre-filtro barato para el índice espacial
+// antes de aplicar la distancia exacta.
+func BoundsAround(lat, lng, radiusKm float64) (float64, float64, float64, float64) {
+	dLat := radiusKm / EarthRadiusKm * 180 / math.Pi
+
+	// Cerca de los polos el coseno tiende a 0; acotamos para no dividir por cero
+	cosLat := math.Max(math.Cos(toRadians(lat)), 0.01)
+	dLng := dLat / cosLat
+
+	return math.Max(lat-dLat, -90), math.Max(lng-dLng, -180),
+		math.Min(lat+dLat, 90), math.Min(lng+dLng, 180)
+}
+
+// ValidCoordinate comprueba que la pareja lat/lng está dentro del rango WGS84
+func ValidCoordinate(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// ClusterCellSize devuelve el lado (en grados) de la celda de agrupación para un
+// nivel de zoom de mapa web (teselas de 256px): los pines que caen a menos de
+// radiusPx píxeles en pantalla comparten celda.
+func ClusterCellSize(zoom int, radiusPx float64) float64 {
+	return radiusPx * 360 / (256 * math.Pow(2, float64(zoom)))
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -0,0 +1,36 @@
+package geo
+
+// Tipos mínimos de GeoJSON (RFC 7946) para exportar puntos.
+// Las coordenadas van en orden [longitud, latitud], como exige el estándar.
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id,omitempty"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type Geometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// NewFeatureCollection crea una colección vacía (features: [] y no null)
+func NewFeatureCollection() FeatureCollection {
+	return FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+}
+
+// NewPointFeature crea un Feature de tipo Point a partir de lat/lng
+func NewPointFeature(id string, lat, lng float64, properties map[string]interface{}) Feature {
+	return Feature{
+		Type:       "Feature",
+		ID:         id,
+		Geometry:   Geometry{Type: "Point", Coordinates: []float64{lng, lat}},
+		Properties: properties,
+	}
+}
@@ -0,0 +1,51 @@
+package geo
+
+import "math"
+
+// MaxTileLatitude es el límite de la proyección Web Mercator
+const MaxTileLatitude = 85.05112878
+
+// ValidTile comprueba que z/x/y es una tesela existente del esquema XYZ
+func ValidTile(z, x, y int) bool {
+	if z < 0 || z > 22 {
+		return false
+	}
+	n := 1 << uint(z)
+	return x >= 0 && x < n && y >= 0 && y < n
+}
+
+// TileBounds devuelve (swLat, swLng, neLat, neLng) de la tesela z/x/y.
+// bufferRatio amplía el rectángulo (0.0625 = 1/16 de tesela por cada lado) para
+// que los iconos en el borde no se corten entre teselas vecinas.
+func TileBounds(z, x, y int, bufferRatio float64) (float64, float64, float64, float64) {
+	n := math.Pow(2, float64(z))
+	fx, fy := float64(x), float64(y)
+
+	west := (fx-bufferRatio)/n*360 - 180
+	east := (fx+1+bufferRatio)/n*360 - 180
+	north := tileYToLat(fy-bufferRatio, n)
+	south := tileYToLat(fy+1+bufferRatio, n)
+
+	return math.Max(south, -MaxTileLatitude), math.Max(west, -180),
+		math.Min(north, MaxTileLatitude), math.Min(east, 180)
+}
+
+// ProjectToTile convierte una coordenada a píxeles locales de la tesela z/x/y con
+// la resolución extent (4096 en MVT). Puede devolver valores fuera de [0, extent)
+// para los puntos del buffer.
+func ProjectToTile(lat, lng float64, z, x, y int, extent int) (int, int) {
+	n := math.Pow(2, float64(z))
+	lat = math.Max(math.Min(lat, MaxTileLatitude), -MaxTileLatitude)
+	latRad := toRadians(lat)
+
+	tx := (lng + 180) / 360 * n
+	ty := (1 - math.Log(math.Tan(latRad)+1/math.Cos(latRad))/math.Pi) / 2 * n
+
+	px := int(math.Round((tx - float64(x)) * float64(extent)))
+	py := int(math.Round((ty - float64(y)) * float64(extent)))
+	return px, py
+}
+
+func tileYToLat(ty, n float64) float64 {
+	return math.Atan(math.Sinh(math.Pi*(1-2*ty/n))) * 180 / math.Pi
+}
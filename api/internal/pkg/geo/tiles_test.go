@@ -0,0 +1,78 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValidTile(t *testing.T) {
+	tests := []struct {
+		z, x, y int
+		want    bool
+	}{
+		{0, 0, 0, true},
+		{1, 1, 1, true},
+		{1, 2, 0, false},
+		{5, 31, 31, true},
+		{5, 0, 32, false},
+		{3, -1, 0, false},
+		{-1, 0, 0, false},
+		{23, 0, 0, false},
+	}
+	for _, tt := range tests {
+		if got := ValidTile(tt.z, tt.x, tt.y); got != tt.want {
+			t.Errorf("ValidTile(%d, %d, %d) = %v, se esperaba %v", tt.z, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestTileBounds(t *testing.T) {
+	tests := []struct {
+		name         string
+		z, x, y      int
+		buffer       float64
+		swLat, swLng float64
+		neLat, neLng float64
+	}{
+		{"mundo", 0, 0, 0, 0, -MaxTileLatitude, -180, MaxTileLatitude, 180},
+		{"cuadrante noreste", 1, 1, 0, 0, 0, 0, MaxTileLatitude, 180},
+		{"el buffer no sale del mundo", 0, 0, 0, 0.0625, -MaxTileLatitude, -180, MaxTileLatitude, 180},
+		{"buffer de 1/16 de tesela", 2, 1, 1, 0.0625, -5.6160, -95.625, 68.6566, 5.625},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swLat, swLng, neLat, neLng := TileBounds(tt.z, tt.x, tt.y, tt.buffer)
+			got := []float64{swLat, swLng, neLat, neLng}
+			want := []float64{tt.swLat, tt.swLng, tt.neLat, tt.neLng}
+			for i := range want {
+				if math.Abs(got[i]-want[i]) > 1e-4 {
+					t.Fatalf("TileBounds = %v, se esperaba %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestProjectToTile(t *testing.T) {
+	const extent = 4096
+	tests := []struct {
+		name     string
+		lat, lng float64
+		z, x, y  int
+		px, py   int
+	}{
+		{"esquina noroeste del mundo", MaxTileLatitude, -180, 0, 0, 0, 0, 0},
+		{"centro del mundo", 0, 0, 0, 0, 0, extent / 2, extent / 2},
+		{"origen de la tesela sureste", 0, 0, 1, 1, 1, 0, 0},
+		{"fuera de la tesela (buffer)", 0, 0, 1, 0, 0, extent, extent},
+		{"latitud más allá del límite", 89, -180, 0, 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			px, py := ProjectToTile(tt.lat, tt.lng, tt.z, tt.x, tt.y, extent)
+			if px != tt.px || py != tt.py {
+				t.Errorf("ProjectToTile = (%d, %d), se esperaba (%d, %d)", px, py, tt.px, tt.py)
+			}
+		})
+	}
+}
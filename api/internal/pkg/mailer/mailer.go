@@ -0,0 +1,238 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Configuración (variables de entorno):
+//
+//	MAIL_BACKEND   smtp | dev | file | stdout (por defecto stdout)
+//	               dev = SMTP sin TLS ni auth contra un capturador local (Mailpit en localhost:1025)
+//	MAIL_FROM      remitente, p. ej. "Veterimap <no-reply@veterimap.com>"
+//	SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD
+//	SMTP_TLS       starttls (por defecto) | tls | none
+//	MAIL_DIR       carpeta de los .eml con MAIL_BACKEND=file
+//	APP_URL        URL del frontend para los enlaces de los correos
+type Config struct {
+	Backend      string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPTLS      string
+	Dir          string
+	AppURL       string
+	MaxAttempts  int           // Intentos por correo antes de darlo por perdido
+	Backoff      time.Duration // Espera tras el primer fallo; se duplica en cada reintento
+	QueueSize    int
+}
+
+const (
+	BackendSMTP   = "smtp"
+	BackendDev    = "dev"
+	BackendFile   = "file"
+	BackendStdout = "stdout"
+)
+
+var ErrQueueFull = errors.New("la cola de correo está llena")
+
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Backend:      strings.ToLower(os.Getenv("MAIL_BACKEND")),
+		From:         os.Getenv("MAIL_FROM"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		SMTPTLS:      strings.ToLower(os.Getenv("SMTP_TLS")),
+		Dir:          os.Getenv("MAIL_DIR"),
+		AppURL:       os.Getenv("APP_URL"),
+		MaxAttempts:  4,
+		Backoff:      2 * time.Second,
+		QueueSize:    256,
+	}
+	if cfg.Backend == "" {
+		cfg.Backend = BackendStdout
+	}
+	if cfg.From == "" {
+		cfg.From = "Veterimap <no-reply@veterimap.com>"
+	}
+	if cfg.AppURL == "" {
+		cfg.AppURL = "http://localhost:5173"
+	}
+	if v := os.Getenv("SMTP_PORT"); v != "" {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, fmt.Errorf("SMTP_PORT no válido: %q", v)
+		}
+		cfg.SMTPPort = port
+	}
+
+	if cfg.Backend == BackendDev {
+		cfg.Backend, cfg.SMTPTLS = BackendSMTP, "none"
+		if cfg.SMTPHost == "" {
+			cfg.SMTPHost = "localhost"
+		}
+		if cfg.SMTPPort == 0 {
+			cfg.SMTPPort = 1025
+		}
+	}
+	return cfg, nil
+}
+
+// Sender entrega un mensaje ya renderizado
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Message es un correo listo para enviar, con versión HTML y texto
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer renderiza las plantillas y entrega los correos en segundo plano con
+// reintentos, para que una caída del SMTP no bloquee ni rompa las peticiones
+type Mailer struct {
+	sender    Sender
+	templates *Templates
+	cfg       Config
+
+	queue chan Message
+	wg    sync.WaitGroup
+	once  sync.Once
+}
+
+func New(cfg Config) (*Mailer, error) {
+	var sender Sender
+	switch cfg.Backend {
+	case BackendSMTP:
+		if cfg.SMTPHost == "" {
+			return nil, errors.New("MAIL_BACKEND=smtp necesita SMTP_HOST")
+		}
+		sender = NewSMTPSender(cfg)
+	case BackendFile:
+		if cfg.Dir == "" {
+			return nil, errors.New("MAIL_BACKEND=file necesita MAIL_DIR")
+		}
+		sender = &FileSender{Dir: cfg.Dir}
+	case BackendStdout:
+		sender = StdoutSender{}
+	default:
+		return nil, fmt.Errorf("MAIL_BACKEND desconocido: %q (smtp, dev, file o stdout)", cfg.Backend)
+	}
+
+	templates, err := LoadTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	m := &Mailer{sender: sender, templates: templates, cfg: cfg, queue: make(chan Message, cfg.QueueSize)}
+	m.wg.Add(1)
+	go m.worker()
+	return m, nil
+}
+
+// NewFromEnv es New con ConfigFromEnv
+func NewFromEnv() (*Mailer, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return New(cfg)
+}
+
+// Render prepara el correo de una plantilla. data se completa con AppName y AppURL.
+func (m *Mailer) Render(to, locale, template string, data map[string]interface{}) (Message, error) {
+	vars := map[string]interface{}{"AppName": "Veterimap", "AppURL": strings.TrimRight(m.cfg.AppURL, "/")}
+	for k, v := range data {
+		vars[k] = v
+	}
+	msg, err := m.templates.Render(template, locale, vars)
+	if err != nil {
+		return Message{}, err
+	}
+	msg.From, msg.To = m.cfg.From, to
+	return msg, nil
+}
+
+// Send encola el correo. Solo falla si la plantilla no se puede renderizar o la
+// cola está llena; los errores de entrega se reintentan y se registran en el log.
+func (m *Mailer) Send(ctx context.Context, to, locale, template string, data map[string]interface{}) error {
+	msg, err := m.Render(to, locale, template, data)
+	if err != nil {
+		return err
+	}
+	select {
+	case m.queue <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Deliver envía el correo en el momento, con reintentos (herramientas de línea de comandos)
+func (m *Mailer) Deliver(ctx context.Context, to, locale, template string, data map[string]interface{}) error {
+	msg, err := m.Render(to, locale, template, data)
+	if err != nil {
+		return err
+	}
+	return m.deliver(ctx, msg)
+}
+
+// Close deja de aceptar correos y espera a que se entregue lo pendiente
+func (m *Mailer) Close() {
+	m.once.Do(func() { close(m.queue) })
+	m.wg.Wait()
+}
+
+func (m *Mailer) worker() {
+	defer m.wg.Done()
+	for msg := range m.queue {
+		if err := m.deliver(context.Background(), msg); err != nil {
+			log.Printf("❌ Correo a %s (%q) perdido: %v", msg.To, msg.Subject, err)
+		}
+	}
+}
+
+// deliver reintenta con espera exponencial. Un rechazo permanente (SMTP 5xx) no se reintenta.
+func (m *Mailer) deliver(ctx context.Context, msg Message) error {
+	wait := m.cfg.Backoff
+	var err error
+	for attempt := 1; attempt <= m.cfg.MaxAttempts; attempt++ {
+		if err = m.sender.Send(ctx, msg); err == nil {
+			return nil
+		}
+		if isPermanent(err) || attempt == m.cfg.MaxAttempts {
+			break
+		}
+		log.Printf("⚠️ Error enviando correo a %s (intento %d/%d), reintento en %s: %v", msg.To, attempt, m.cfg.MaxAttempts, wait, err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		wait *= 2
+	}
+	return err
+}
+
+func isPermanent(err error) bool {
+	var smtpErr *textproto.Error
+	return errors.As(err, &smtpErr) && smtpErr.Code >= 500
+}
@@ -0,0 +1,148 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+)
+
+// DefaultLocale es el idioma de respaldo cuando una plantilla no está traducida
+const DefaultLocale = "es"
+
+// Cada plantilla son dos ficheros por idioma en templates/<idioma>/:
+//
+//	<nombre>.txt   {{define "subject"}}…{{end}} y {{define "body"}}…{{end}} (versión texto)
+//	<nombre>.html  {{define "content"}}…{{end}}, que se pinta dentro de layout.html
+//
+//go:embed templates
+var templateFS embed.FS
+
+type template struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// Templates son las plantillas cargadas, por idioma y nombre
+type Templates struct {
+	byLocale map[string]map[string]template
+}
+
+func LoadTemplates() (*Templates, error) {
+	t := &Templates{byLocale: map[string]map[string]template{}}
+
+	locales, err := fs.ReadDir(templateFS, "templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range locales {
+		if !dir.IsDir() {
+			continue
+		}
+		locale := dir.Name()
+		files, err := fs.Glob(templateFS, path.Join("templates", locale, "*.txt"))
+		if err != nil {
+			return nil, err
+		}
+
+		t.byLocale[locale] = map[string]template{}
+		for _, file := range files {
+			name := strings.TrimSuffix(path.Base(file), ".txt")
+			text, err := texttemplate.New(name).Option("missingkey=error").ParseFS(templateFS, file)
+			if err != nil {
+				return nil, fmt.Errorf("plantilla %s/%s: %v", locale, name, err)
+			}
+			html, err := htmltemplate.New(name).Option("missingkey=error").ParseFS(templateFS,
+				"templates/layout.html", path.Join("templates", locale, name+".html"))
+			if err != nil {
+				return nil, fmt.Errorf("plantilla %s/%s: %v", locale, name, err)
+			}
+			t.byLocale[locale][name] = template{text: text, html: html}
+		}
+	}
+
+	if len(t.byLocale[DefaultLocale]) == 0 {
+		return nil, fmt.Errorf("no hay plantillas de correo en %q", DefaultLocale)
+	}
+	return t, nil
+}
+
+// Render pinta asunto, texto y HTML. El idioma admite variantes ("es-ES", "en_GB")
+// y, si no existe, se usa DefaultLocale.
+func (t *Templates) Render(name, locale string, data map[string]interface{}) (Message, error) {
+	tmpl, ok := t.lookup(name, locale)
+	if !ok {
+		return Message{}, fmt.Errorf("plantilla de correo desconocida: %s", name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, "body", data); err != nil {
+		return Message{}, err
+	}
+	htmlData := map[string]interface{}{"Subject": strings.TrimSpace(subject.String())}
+	for k, v := range data {
+		htmlData[k] = v
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, "layout", htmlData); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+// Locale elige el idioma con plantillas que mejor encaja con una cabecera
+// Accept-Language ("en-GB,en;q=0.9,es;q=0.8") o un idioma suelto ("en_GB").
+// Si ninguno está traducido devuelve DefaultLocale.
+func Locale(accept string) string {
+	best, bestQ := DefaultLocale, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		locale := baseLocale(tag)
+		// Solo letras: el idioma acaba en una ruta de templateFS
+		if q <= bestQ || locale == "" || strings.Trim(locale, "abcdefghijklmnopqrstuvwxyz") != "" {
+			continue
+		}
+		if dir, err := fs.Stat(templateFS, path.Join("templates", locale)); err == nil && dir.IsDir() {
+			best, bestQ = locale, q
+		}
+	}
+	return best
+}
+
+// baseLocale se queda con el idioma sin la región: "es-ES" → "es"
+func baseLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		locale = locale[:i]
+	}
+	return locale
+}
+
+func (t *Templates) lookup(name, locale string) (template, bool) {
+	locale = baseLocale(locale)
+	if tmpl, ok := t.byLocale[locale][name]; ok {
+		return tmpl, true
+	}
+	tmpl, ok := t.byLocale[DefaultLocale][name]
+	return tmpl, ok
+}
@@ -0,0 +1,66 @@
+package moderation
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"veterimap-api/internal/pkg/slug"
+)
+
+// Marcas que puede llevar un texto enviado por un profesional. No bloquean nada:
+// ordenan la cola de revisión y avisan al moderador de qué mirar.
+const (
+	FlagLink      = "link"
+	FlagPhone     = "phone"
+	FlagEmail     = "email"
+	FlagProfanity = "profanity"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`(?i)[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}`)
+	linkPattern  = regexp.MustCompile(`(?i)(?:https?://|www\.)\S+|\b[a-z0-9-]+\.(?:com|es|net|org|info|biz|io|me|ly|link|xyz|shop|online|site|store)\b`)
+	// 9 cifras o más con separadores opcionales: móviles y fijos españoles, con o sin prefijo
+	phonePattern = regexp.MustCompile(`(?:\+?\d[\s.\-()]*){9,}`)
+)
+
+// Palabras malsonantes, ya normalizadas (minúsculas y sin tildes, como slug.Make)
+var profanity = map[string]bool{
+	"puta": true, "putas": true, "puto": true, "putos": true, "mierda": true,
+	"cabron": true, "cabrones": true, "gilipollas": true, "joder": true,
+	"capullo": true, "imbecil": true, "subnormal": true, "zorra": true,
+	"fuck": true, "fucking": true, "shit": true, "bitch": true, "asshole": true,
+}
+
+// Scan revisa los textos y devuelve las marcas encontradas, ordenadas y sin repetir
+func Scan(texts ...string) []string {
+	found := map[string]bool{}
+	for _, t := range texts {
+		if t == "" {
+			continue
+		}
+		if emailPattern.MatchString(t) {
+			found[FlagEmail] = true
+		}
+		// Los dominios de los emails no cuentan como enlaces
+		withoutEmails := emailPattern.ReplaceAllString(t, " ")
+		if linkPattern.MatchString(withoutEmails) {
+			found[FlagLink] = true
+		}
+		if phonePattern.MatchString(withoutEmails) {
+			found[FlagPhone] = true
+		}
+		for _, w := range strings.Split(slug.Make(t), "-") {
+			if profanity[w] {
+				found[FlagProfanity] = true
+				break
+			}
+		}
+	}
+
+	flags := make([]string, 0, len(found))
+	for f := range found {
+		flags = append(flags, f)
+	}
+	sort.Strings(flags)
+	return flags
+}
@@ -0,0 +1,192 @@
+// Package mvt codifica teselas Mapbox Vector Tile (especificación v2).
+// Solo soporta geometrías de tipo punto, que es lo que pinta el mapa de Veterimap,
+// y escribe el protobuf a mano para no añadir dependencias.
+package mvt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// DefaultExtent es la resolución interna de la tesela recomendada por la especificación
+const DefaultExtent = 4096
+
+// Feature es un punto con sus propiedades, ya proyectado a coordenadas de tesela
+type Feature struct {
+	ID         uint64
+	X, Y       int
+	Properties map[string]interface{} // string, float64, int, bool
+}
+
+// Layer es una capa con nombre dentro de la tesela
+type Layer struct {
+	Name     string
+	Extent   uint32
+	Features []Feature
+}
+
+// Campos del esquema vector_tile.proto
+const (
+	tileLayers = 3
+
+	layerName     = 1
+	layerFeatures = 2
+	layerKeys     = 3
+	layerValues   = 4
+	layerExtent   = 5
+	layerVersion  = 15
+
+	featureID       = 1
+	featureTags     = 2
+	featureType     = 3
+	featureGeometry = 4
+
+	valueString = 1
+	valueDouble = 3
+	valueSint   = 6
+	valueBool   = 7
+
+	geomTypePoint = 1
+	cmdMoveTo     = 1
+)
+
+// Encode serializa las capas en una tesela MVT. Una tesela sin capas es válida (0 bytes).
+func Encode(layers ...Layer) ([]byte, error) {
+	var tile []byte
+	for _, l := range layers {
+		layer, err := encodeLayer(l)
+		if err != nil {
+			return nil, fmt.Errorf("capa %s: %v", l.Name, err)
+		}
+		tile = appendBytes(tile, tileLayers, layer)
+	}
+	return tile, nil
+}
+
+func encodeLayer(l Layer) ([]byte, error) {
+	extent := l.Extent
+	if extent == 0 {
+		extent = DefaultExtent
+	}
+
+	var keys []string
+	keyIndex := map[string]uint32{}
+	var values [][]byte
+	valueIndex := map[string]uint32{}
+
+	var buf []byte
+	buf = appendVarintField(buf, layerVersion, 2)
+	buf = appendBytes(buf, layerName, []byte(l.Name))
+
+	for _, f := range l.Features {
+		// Ordenamos las claves para que la misma entrada produzca siempre los mismos bytes (ETag estable)
+		names := make([]string, 0, len(f.Properties))
+		for k := range f.Properties {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+
+		var tags []uint64
+		for _, k := range names {
+			encoded, err := encodeValue(f.Properties[k])
+			if err != nil {
+				return nil, fmt.Errorf("propiedad %s: %v", k, err)
+			}
+
+			ki, ok := keyIndex[k]
+			if !ok {
+				ki = uint32(len(keys))
+				keyIndex[k] = ki
+				keys = append(keys, k)
+			}
+			vi, ok := valueIndex[string(encoded)]
+			if !ok {
+				vi = uint32(len(values))
+				valueIndex[string(encoded)] = vi
+				values = append(values, encoded)
+			}
+			tags = append(tags, uint64(ki), uint64(vi))
+		}
+
+		geometry := []uint64{
+			uint64(cmdMoveTo&0x7) | 1<<3, // MoveTo con un único punto
+			zigzag(int64(f.X)),
+			zigzag(int64(f.Y)),
+		}
+
+		var feature []byte
+		if f.ID != 0 {
+			feature = appendVarintField(feature, featureID, f.ID)
+		}
+		feature = appendPacked(feature, featureTags, tags)
+		feature = appendVarintField(feature, featureType, geomTypePoint)
+		feature = appendPacked(feature, featureGeometry, geometry)
+
+		buf = appendBytes(buf, layerFeatures, feature)
+	}
+
+	for _, k := range keys {
+		buf = appendBytes(buf, layerKeys, []byte(k))
+	}
+	for _, v := range values {
+		buf = appendBytes(buf, layerValues, v)
+	}
+	buf = appendVarintField(buf, layerExtent, uint64(extent))
+
+	return buf, nil
+}
+
+func encodeValue(v interface{}) ([]byte, error) {
+	var buf []byte
+	switch t := v.(type) {
+	case string:
+		buf = appendBytes(buf, valueString, []byte(t))
+	case float64:
+		buf = appendTag(buf, valueDouble, 1)
+		buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(t))
+	case int:
+		buf = appendVarintField(buf, valueSint, zigzag(int64(t)))
+	case int64:
+		buf = appendVarintField(buf, valueSint, zigzag(t))
+	case bool:
+		b := uint64(0)
+		if t {
+			b = 1
+		}
+		buf = appendVarintField(buf, valueBool, b)
+	default:
+		return nil, fmt.Errorf("tipo no soportado %T", v)
+	}
+	return buf, nil
+}
+
+// --- Codificación protobuf mínima ---
+
+func appendTag(buf []byte, field int, wireType int) []byte {
+	return binary.AppendUvarint(buf, uint64(field)<<3|uint64(wireType))
+}
+
+func appendVarintField(buf []byte, field int, v uint64) []byte {
+	buf = appendTag(buf, field, 0)
+	return binary.AppendUvarint(buf, v)
+}
+
+func appendBytes(buf []byte, field int, b []byte) []byte {
+	buf = appendTag(buf, field, 2)
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+func appendPacked(buf []byte, field int, vs []uint64) []byte {
+	var packed []byte
+	for _, v := range vs {
+		packed = binary.AppendUvarint(packed, v)
+	}
+	return appendBytes(buf, field, packed)
+}
+
+func zigzag(n int64) uint64 {
+	return uint64((n << 1) ^ (n >> 63))
+}
@@ -16,6 +16,16 @@ func JSON(w http.ResponseWriter, status int, data interface{}) {
 	}
 }
 
+// GeoJSON responde igual que JSON pero con el media type de RFC 7946,
+// para que herramientas GIS (QGIS, ogr2ogr) reconozcan el formato
+func GeoJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/geo+json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Error al codificar respuesta", http.StatusInternalServerError)
+	}
+}
+
 // Error responde con un formato estándar de error para que el Frontend 
 // siempre sepa dónde buscar el mensaje: response.error
 func Error(w http.ResponseWriter, status int, message string) {
@@ -24,4 +34,15 @@ func Error(w http.ResponseWriter, status int, message string) {
 	json.NewEncoder(w).Encode(map[string]string{
 		"error": message,
 	})
-}
\ No newline at end of file
+}
+
+// ErrorCode es Error con un código estable (response.code) para que el Frontend
+// decida qué hacer sin depender del texto del mensaje
+func ErrorCode(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": message,
+		"code":  code,
+	})
+}
@@ -0,0 +1,61 @@
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Fallback es el slug de un nombre sin letras ni dígitos aprovechables
+const Fallback = "profesional"
+
+// Letras que no se descomponen con NFD; unaccent las traduce igual
+var ligatures = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "Æ", "AE", "œ", "oe", "Œ", "OE",
+	"ø", "o", "Ø", "O", "đ", "d", "Đ", "D", "ł", "l", "Ł", "L",
+)
+
+// Make construye el slug canónico uniendo las partes: "Clínica Sol", "Madrid" ->
+// "clinica-sol-madrid". Quita tildes, pasa a minúsculas y deja solo [a-z0-9] separados
+// por guiones. Es la misma regla que la función SQL slugify de las migraciones.
+func Make(parts ...string) string {
+	raw := strings.Join(parts, " ")
+
+	stripper := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	plain, _, err := transform.String(stripper, ligatures.Replace(raw))
+	if err != nil {
+		plain = raw
+	}
+
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(plain) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// WithSuffix numera un slug repetido: ("clinica-sol-madrid", 2) -> "clinica-sol-madrid-2"
+func WithSuffix(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	return base + "-" + strconv.Itoa(n)
+}
+
+// Valid indica si s ya está en forma canónica
+func Valid(s string) bool {
+	return s != "" && Make(s) == s
+}
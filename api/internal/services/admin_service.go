@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"veterimap-api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type adminService struct {
+	repo          domain.AdminRepository
+	verifications domain.VerificationRepository
+	mailer        domain.Mailer
+}
+
+func NewAdminService(repo domain.AdminRepository, verifications domain.VerificationRepository, mailer domain.Mailer) domain.AdminService {
+	return &adminService{repo: repo, verifications: verifications, mailer: mailer}
+}
+
+func (s *adminService) SearchUsers(ctx context.Context, f domain.UserFilter) (int, []domain.AdminUser, error) {
+	f.Query = strings.TrimSpace(f.Query)
+	return s.repo.SearchUsers(ctx, f)
+}
+
+func (s *adminService) GetUser(ctx context.Context, id uuid.UUID) (*domain.AdminUser, error) {
+	return s.repo.GetAdminUser(ctx, id)
+}
+
+// UpdateSubscription aplica un cambio parcial sobre el plan y el fin del periodo de prueba
+func (s *adminService) UpdateSubscription(ctx context.Context, adminID, id uuid.UUID, change domain.SubscriptionChange) (*domain.AdminUser, error) {
+	u, err := s.repo.GetAdminUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	status, trialEndsAt := u.SubscriptionStatus, u.TrialEndsAt
+	if change.Status != nil {
+		status = strings.ToLower(strings.TrimSpace(*change.Status))
+		if !validSubscriptionStatus(status) {
+			return nil, fmt.Errorf("%w: subscription_status debe ser uno de %s", domain.ErrAdminInvalid, strings.Join(domain.SubscriptionStatuses, ", "))
+		}
+	}
+	if change.TrialEndsAt != nil {
+		trialEndsAt = *change.TrialEndsAt
+	}
+
+	if err := s.repo.UpdateSubscription(ctx, id, status, trialEndsAt); err != nil {
+		return nil, err
+	}
+	log.Printf("🛠️ Admin %s cambia la suscripción de %s: %s (trial hasta %v)", adminID, u.Email, status, trialEndsAt)
+	return s.repo.GetAdminUser(ctx, id)
+}
+
+// ResendVerification genera un código nuevo para una cuenta sin verificar. El admin
+// no está sujeto al límite de reenvíos, pero el envío cuenta para el del usuario.
+func (s *adminService) ResendVerification(ctx context.Context, adminID, id uuid.UUID) error {
+	u, err := s.repo.GetAdminUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if u.IsVerified {
+		return domain.ErrAlreadyVerified
+	}
+
+	code := newNumericCode()
+	if err := s.verifications.SetVerificationCode(ctx, id, code, time.Now().Add(verificationCodeTTL)); err != nil {
+		return err
+	}
+	if err := sendVerificationEmail(ctx, s.mailer, u.Email, u.Name, u.Locale, code); err != nil {
+		return err
+	}
+	log.Printf("📧 Código de verificación reenviado a %s por el admin %s", u.Email, adminID)
+	return nil
+}
+
+func (s *adminService) SearchEntities(ctx context.Context, f domain.EntityFilter) (int, []domain.EntityInfo, error) {
+	f.Query = strings.TrimSpace(f.Query)
+	return s.repo.SearchEntities(ctx, f)
+}
+
+// SetEntityActive oculta o vuelve a mostrar una ficha en el mapa y los listados
+func (s *adminService) SetEntityActive(ctx context.Context, adminID, id uuid.UUID, active bool) (*domain.EntityInfo, error) {
+	if err := s.repo.SetEntityActive(ctx, id, active); err != nil {
+		return nil, err
+	}
+	log.Printf("🛠️ Admin %s cambia is_active de la ficha %s a %v", adminID, id, active)
+	return s.repo.GetEntity(ctx, id)
+}
+
+func (s *adminService) SetEntityStatus(ctx context.Context, adminID, id uuid.UUID, status string) (*domain.EntityInfo, error) {
+	status = strings.ToUpper(strings.TrimSpace(status))
+	switch status {
+	case domain.EntityStatusProspect, domain.EntityStatusClaimed, domain.EntityStatusVerified:
+	default:
+		return nil, fmt.Errorf("%w: status debe ser PROSPECT, CLAIMED o VERIFIED", domain.ErrAdminInvalid)
+	}
+
+	if err := s.repo.SetEntityStatus(ctx, id, status); err != nil {
+		return nil, err
+	}
+	log.Printf("🛠️ Admin %s cambia el estado de la ficha %s a %s", adminID, id, status)
+	return s.repo.GetEntity(ctx, id)
+}
+
+func validSubscriptionStatus(status string) bool {
+	for _, s := range domain.SubscriptionStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+	"veterimap-api/internal/auth"
+	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/mailer"
+
+	"github.com/google/uuid"
+)
+
+const (
+	claimCodeTTL         = 30 * time.Minute
+	claimCodeMaxAttempts = 5
+
+	// Cada reclamación nueva trae otro código con sus intentos a cero y otro correo al
+	// contacto de la ficha: sin estos límites se podría probar códigos sin fin o
+	// usar la ficha para enviar correos a su contacto
+	claimRequestCooldown    = time.Minute // Entre dos reclamaciones de la misma ficha
+	claimMaxPerEntityPerDay = 3
+	claimMaxPerUserPerDay   = 10
+)
+
+type claimService struct {
+	claims   domain.ClaimRepository
+	profiles domain.ProfileRepository
+	users    domain.UserRepository
+	mailer   domain.Mailer
+}
+
+func NewClaimService(claims domain.ClaimRepository, profiles domain.ProfileRepository, users domain.UserRepository, mailer domain.Mailer) domain.ClaimService {
+	return &claimService{claims: claims, profiles: profiles, users: users, mailer: mailer}
+}
+
+// RequestClaim abre una reclamación sobre una ficha sin titular. Con EMAIL se envía
+// un código al contacto que figura en la ficha (no el del usuario: así se demuestra
+// el control del negocio). Con ADMIN queda en cola de revisión manual, y con PHONE
+// también mientras no haya envío de SMS: el admin llama al teléfono de la ficha.
+func (s *claimService) RequestClaim(ctx context.Context, userID, entityID uuid.UUID, method domain.ClaimMethod, evidence string) (*domain.ProfileClaim, error) {
+	entity, err := s.profiles.GetProfileDetail(ctx, entityID.String())
+	if err != nil {
+		return nil, err
+	}
+	if entity.UserID != nil || entity.Status != domain.EntityStatusProspect {
+		return nil, domain.ErrClaimNotAllowed
+	}
+	if err := s.checkClaimRate(ctx, userID, entityID); err != nil {
+		return nil, err
+	}
+
+	claim := &domain.ProfileClaim{
+		ID:         uuid.New(),
+		EntityID:   entityID,
+		EntityName: entity.Name,
+		UserID:     userID,
+		Method:     method,
+		Status:     domain.ClaimStatusPending,
+		Evidence:   strings.TrimSpace(evidence),
+	}
+
+	var code string
+	switch method {
+	case domain.ClaimMethodEmail:
+		claim.Destination = strings.TrimSpace(entity.ProfileData.Contact.Email)
+	case domain.ClaimMethodPhone:
+		claim.Destination = strings.TrimSpace(entity.ProfileData.Contact.Phone)
+	case domain.ClaimMethodAdmin:
+	default:
+		return nil, fmt.Errorf("método de verificación no válido: %s", method)
+	}
+
+	if method != domain.ClaimMethodAdmin && claim.Destination == "" {
+		return nil, domain.ErrClaimNoContact
+	}
+	if method == domain.ClaimMethodEmail {
+		code = newNumericCode()
+		hash, err := auth.HashPassword(code)
+		if err != nil {
+			return nil, err
+		}
+		expires := time.Now().Add(claimCodeTTL)
+		claim.CodeHash = hash
+		claim.ExpiresAt = &expires
+	}
+
+	if err := s.claims.CreateClaim(ctx, claim); err != nil {
+		return nil, err
+	}
+
+	if method == domain.ClaimMethodEmail {
+		// Del idioma del negocio no sabemos nada: el código lo espera quien reclama
+		locale := mailer.DefaultLocale
+		if u, err := s.users.GetUserByID(ctx, userID); err == nil {
+			locale = u.Locale
+		}
+		err := s.mailer.Send(ctx, claim.Destination, locale, domain.EmailClaimCode, map[string]interface{}{
+			"EntityName": entity.Name,
+			"Code":       code,
+			"TTLMinutes": int(claimCodeTTL.Minutes()),
+		})
+		if err != nil {
+			log.Printf("❌ No se pudo enviar el código de reclamación a %s: %v", claim.Destination, err)
+		}
+	} else {
+		log.Printf("📝 Reclamación de '%s' (%s) pendiente de revisión manual (%s)", entity.Name, method, claim.ID)
+	}
+
+	claim.Destination = maskDestination(method, claim.Destination)
+	return claim, nil
+}
+
+// checkClaimRate aplica los límites de reclamaciones en las últimas 24 h: por ficha
+// (pausa mínima y máximo diario) y por usuario (máximo diario en total)
+func (s *claimService) checkClaimRate(ctx context.Context, userID, entityID uuid.UUID) error {
+	now := time.Now()
+	recent, err := s.claims.RecentClaims(ctx, userID, now.Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+
+	// Al estar ordenadas de más reciente a más antigua, el último elemento es el que
+	// antes sale de la ventana y libera un hueco
+	var sameEntity []time.Time
+	for _, c := range recent {
+		if c.EntityID == entityID {
+			sameEntity = append(sameEntity, c.CreatedAt)
+		}
+	}
+	if len(sameEntity) > 0 && now.Sub(sameEntity[0]) < claimRequestCooldown {
+		return &domain.ClaimThrottledError{RetryAfter: claimRequestCooldown - now.Sub(sameEntity[0])}
+	}
+	if len(sameEntity) >= claimMaxPerEntityPerDay {
+		return &domain.ClaimThrottledError{RetryAfter: sameEntity[len(sameEntity)-1].Add(24 * time.Hour).Sub(now)}
+	}
+	if len(recent) >= claimMaxPerUserPerDay {
+		return &domain.ClaimThrottledError{RetryAfter: recent[len(recent)-1].CreatedAt.Add(24 * time.Hour).Sub(now)}
+	}
+	return nil
+}
+
+// VerifyClaim comprueba el código y, si es correcto, asigna la ficha al profesional
+func (s *claimService) VerifyClaim(ctx context.Context, userID, claimID uuid.UUID, code string) (*domain.ProfileClaim, error) {
+	claim, err := s.claims.GetClaim(ctx, claimID)
+	if err != nil {
+		return nil, err
+	}
+	if claim.UserID != userID {
+		// No revelamos reclamaciones ajenas
+		return nil, domain.ErrClaimNotFound
+	}
+	// Sin código (ADMIN, PHONE) solo la resuelve un admin
+	if claim.Status != domain.ClaimStatusPending || claim.CodeHash == "" {
+		return nil, domain.ErrClaimNotPending
+	}
+	if claim.ExpiresAt == nil || time.Now().After(*claim.ExpiresAt) {
+		return nil, domain.ErrClaimCodeExpired
+	}
+
+	// El intento se gasta antes de comparar: el límite se comprueba y se suma a la vez
+	attempts, err := s.claims.ConsumeClaimAttempt(ctx, claimID, claimCodeMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !auth.CheckPasswordHash(strings.TrimSpace(code), claim.CodeHash) {
+		if attempts >= claimCodeMaxAttempts {
+			return nil, domain.ErrClaimTooManyAttempts
+		}
+		return nil, domain.ErrClaimCodeInvalid
+	}
+
+	if err := s.claims.CompleteClaim(ctx, claimID, nil); err != nil {
+		return nil, err
+	}
+	log.Printf("✅ Ficha '%s' reclamada por el usuario %s", claim.EntityName, userID)
+	return s.claims.GetClaim(ctx, claimID)
+}
+
+func (s *claimService) ListMyClaims(ctx context.Context, userID uuid.UUID) ([]domain.ProfileClaim, error) {
+	claims, err := s.claims.ListClaimsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range claims {
+		claims[i].Destination = maskDestination(claims[i].Method, claims[i].Destination)
+	}
+	return claims, nil
+}
+
+func (s *claimService) ListClaims(ctx context.Context, status domain.ClaimStatus) ([]domain.ProfileClaim, error) {
+	if status == "" {
+		status = domain.ClaimStatusPending
+	}
+	return s.claims.ListClaimsByStatus(ctx, status)
+}
+
+// ApproveClaim permite a un admin resolver cualquier reclamación pendiente,
+// también las de código (p. ej. si la ficha tiene un email antiguo)
+func (s *claimService) ApproveClaim(ctx context.Context, adminID, claimID uuid.UUID) (*domain.ProfileClaim, error) {
+	if err := s.claims.CompleteClaim(ctx, claimID, &adminID); err != nil {
+		return nil, err
+	}
+	log.Printf("✅ Reclamación %s aprobada por el admin %s", claimID, adminID)
+	return s.claims.GetClaim(ctx, claimID)
+}
+
+func (s *claimService) RejectClaim(ctx context.Context, adminID, claimID uuid.UUID, note string) (*domain.ProfileClaim, error) {
+	if err := s.claims.RejectClaim(ctx, claimID, adminID, strings.TrimSpace(note)); err != nil {
+		if errors.Is(err, domain.ErrClaimNotPending) {
+			// Distinguimos "no existe" de "ya resuelta"
+			if _, getErr := s.claims.GetClaim(ctx, claimID); getErr != nil {
+				return nil, getErr
+			}
+		}
+		return nil, err
+	}
+	return s.claims.GetClaim(ctx, claimID)
+}
+
+// newNumericCode genera un código de 6 cifras (mismo formato que el de registro)
+func newNumericCode() string {
+	n, _ := rand.Int(rand.Reader, big.NewInt(900000))
+	return fmt.Sprintf("%06d", n.Int64()+100000)
+}
+
+// maskDestination oculta el contacto de la ficha: c•••@clinica.es, ••••••45 67
+func maskDestination(method domain.ClaimMethod, dest string) string {
+	if dest == "" {
+		return ""
+	}
+	if method == domain.ClaimMethodEmail {
+		at := strings.LastIndex(dest, "@")
+		if at <= 0 {
+			return "•••"
+		}
+		return dest[:1] + "•••" + dest[at:]
+	}
+
+	digits := []rune(strings.ReplaceAll(dest, " ", ""))
+	if len(digits) <= 4 {
+		return "••••"
+	}
+	return strings.Repeat("•", len(digits)-4) + string(digits[len(digits)-4:])
+}
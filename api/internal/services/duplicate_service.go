@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"log"
+	"veterimap-api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type duplicateService struct {
+	repo domain.DuplicateRepository
+}
+
+func NewDuplicateService(repo domain.DuplicateRepository) domain.DuplicateService {
+	return &duplicateService{repo: repo}
+}
+
+func (s *duplicateService) ListDuplicates(ctx context.Context, minScore float64, limit, offset int) (int, []domain.DuplicateCandidate, error) {
+	if minScore <= 0 || minScore > 1 {
+		minScore = domain.DuplicateDefaultScore
+	}
+	return s.repo.FindDuplicates(ctx, minScore, limit, offset)
+}
+
+// Dismiss marca la pareja como revisada para que no vuelva a salir en la cola
+func (s *duplicateService) Dismiss(ctx context.Context, adminID, a, b uuid.UUID) error {
+	if a == b {
+		return domain.ErrMergeSameEntity
+	}
+	return s.repo.DismissDuplicate(ctx, a, b, adminID)
+}
+
+func (s *duplicateService) Merge(ctx context.Context, adminID, survivorID, mergedID uuid.UUID) (*domain.EntityMerge, error) {
+	m := &domain.EntityMerge{
+		ID:         uuid.New(),
+		SurvivorID: survivorID,
+		MergedID:   mergedID,
+		MergedBy:   adminID,
+	}
+	if err := s.repo.MergeEntities(ctx, m); err != nil {
+		return nil, err
+	}
+	log.Printf("🔀 Admin %s fusiona la ficha %s en %s", adminID, mergedID, survivorID)
+	return m, nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"veterimap-api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type moderationService struct {
+	revisions domain.RevisionRepository
+	profiles  domain.ProfileRepository
+}
+
+func NewModerationService(revisions domain.RevisionRepository, profiles domain.ProfileRepository) domain.ModerationService {
+	return &moderationService{revisions: revisions, profiles: profiles}
+}
+
+func (s *moderationService) ListRevisions(ctx context.Context, status domain.RevisionStatus, limit, offset int) (int, []domain.ProfileRevision, error) {
+	if status == "" {
+		status = domain.RevisionStatusPending
+	}
+	return s.revisions.ListRevisions(ctx, status, limit, offset)
+}
+
+// GetRevision añade la versión publicada para que el moderador compare
+func (s *moderationService) GetRevision(ctx context.Context, id uuid.UUID) (*domain.ProfileRevision, error) {
+	rev, err := s.revisions.GetRevision(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.profiles.GetProfessionalProfileByUserID(ctx, rev.UserID)
+	if err == nil && current.ID == rev.EntityID {
+		rev.Current = current
+	}
+	return rev, nil
+}
+
+func (s *moderationService) ApproveRevision(ctx context.Context, adminID, id uuid.UUID) (*domain.ProfileRevision, error) {
+	rev, err := s.revisions.GetRevision(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rev.Status != domain.RevisionStatusPending {
+		return nil, domain.ErrRevisionNotPending
+	}
+
+	// Las revisiones antiguas pueden venir sin nombre: sin él no hay slug que publicar
+	base := profileSlug(rev.Name, rev.ProfileData.MainCity())
+	if base == nil {
+		return nil, domain.ErrProfileNameRequired
+	}
+	if err := s.revisions.ApproveRevision(ctx, id, adminID, *base); err != nil {
+		return nil, err
+	}
+	log.Printf("✅ Revisión %s aprobada por %s", id, adminID)
+	return s.revisions.GetRevision(ctx, id)
+}
+
+func (s *moderationService) RejectRevision(ctx context.Context, adminID, id uuid.UUID, reason string) (*domain.ProfileRevision, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, domain.ErrRevisionNoReason
+	}
+
+	if err := s.revisions.RejectRevision(ctx, id, adminID, reason); err != nil {
+		if !errors.Is(err, domain.ErrRevisionNotPending) && !errors.Is(err, domain.ErrRevisionNotFound) {
+			log.Printf("❌ Error rechazando revisión %s: %v", id, err)
+		}
+		return nil, err
+	}
+	return s.revisions.GetRevision(ctx, id)
+}
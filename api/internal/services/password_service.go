@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"veterimap-api/internal/auth"
+	"veterimap-api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const (
+	passwordResetTTL        = time.Hour
+	passwordResetMaxPerHour = 3 // Solicitudes por usuario y hora; las demás se ignoran en silencio
+	passwordMinLength       = 8
+	passwordMaxLength       = 72 // bcrypt ignora lo que pase de 72 bytes
+)
+
+type passwordService struct {
+	users     domain.UserRepository
+	passwords domain.PasswordRepository
+	sessions  domain.SessionRepository
+	mailer    domain.Mailer
+}
+
+func NewPasswordService(users domain.UserRepository, passwords domain.PasswordRepository, sessions domain.SessionRepository, mailer domain.Mailer) domain.PasswordService {
+	return &passwordService{users: users, passwords: passwords, sessions: sessions, mailer: mailer}
+}
+
+// RequestReset genera un enlace de un solo uso. Si el email no existe o se ha
+// superado el límite no se hace nada, pero el cliente recibe la misma respuesta.
+func (s *passwordService) RequestReset(ctx context.Context, email string, meta domain.SessionMeta) error {
+	cleanEmail := strings.ToLower(strings.TrimSpace(email))
+	u, err := s.users.GetByEmail(ctx, cleanEmail)
+	if err != nil {
+		log.Printf("🔐 Recuperación solicitada para un email desconocido (IP %s)", meta.IP)
+		return nil
+	}
+
+	recent, err := s.passwords.CountRecentResets(ctx, u.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if recent >= passwordResetMaxPerHour {
+		log.Printf("🔐 Límite de recuperaciones alcanzado para %s (IP %s)", u.ID, meta.IP)
+		return nil
+	}
+
+	token, hash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+	if err := s.passwords.CreatePasswordReset(ctx, u.ID, hash, meta.IP, time.Now().Add(passwordResetTTL)); err != nil {
+		return err
+	}
+
+	// Un fallo de envío no se devuelve: la respuesta no debe delatar si el email existe
+	err = s.mailer.Send(ctx, u.Email, u.Locale, domain.EmailPasswordReset, map[string]interface{}{
+		"URL":        passwordResetURL(token),
+		"TTLMinutes": int(passwordResetTTL.Minutes()),
+	})
+	if err != nil {
+		log.Printf("❌ No se pudo enviar el enlace de recuperación a %s: %v", u.Email, err)
+	}
+	return nil
+}
+
+// ResetPassword consume el enlace, cambia la contraseña y cierra todas las sesiones
+func (s *passwordService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return domain.ErrResetTokenInvalid
+	}
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+	hash, err := auth.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	userID, err := s.passwords.ConsumePasswordReset(ctx, hashToken(token), hash)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.sessions.RevokeAllSessions(ctx, userID, uuid.Nil, domain.SessionRevokedPassword)
+	if err != nil {
+		return err
+	}
+	log.Printf("🔐 Contraseña recuperada para %s; %d sesiones cerradas", userID, revoked)
+	return nil
+}
+
+// ChangePassword exige la contraseña actual. La sesión desde la que se cambia
+// sigue abierta; las de los demás dispositivos se cierran.
+func (s *passwordService) ChangePassword(ctx context.Context, userID, currentSessionID uuid.UUID, currentPassword, newPassword string) error {
+	current, err := s.passwords.GetPasswordHash(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !auth.CheckPasswordHash(currentPassword, current) {
+		return domain.ErrWrongPassword
+	}
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+	if newPassword == currentPassword {
+		return fmt.Errorf("%w: la nueva contraseña debe ser distinta de la actual", domain.ErrWeakPassword)
+	}
+
+	hash, err := auth.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.passwords.UpdatePassword(ctx, userID, hash); err != nil {
+		return err
+	}
+
+	_, err = s.sessions.RevokeAllSessions(ctx, userID, currentSessionID, domain.SessionRevokedPassword)
+	return err
+}
+
+func validatePassword(p string) error {
+	if utf8.RuneCountInString(p) < passwordMinLength {
+		return fmt.Errorf("%w: debe tener al menos %d caracteres", domain.ErrWeakPassword, passwordMinLength)
+	}
+	if len(p) > passwordMaxLength {
+		return fmt.Errorf("%w: no puede superar los %d bytes", domain.ErrWeakPassword, passwordMaxLength)
+	}
+	return nil
+}
+
+// passwordResetURL es el enlace del frontend que recoge el token (APP_URL en producción)
+func passwordResetURL(token string) string {
+	base := os.Getenv("APP_URL")
+	if base == "" {
+		base = "http://localhost:5173"
+	}
+	return strings.TrimRight(base, "/") + "/reset-password?token=" + token
+}
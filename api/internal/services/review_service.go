@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/slug"
+
+	"github.com/google/uuid"
+)
+
+const (
+	reviewMaxLength       = 2000
+	reviewReplyMaxLength  = 1000
+	reviewReportThreshold = 3 // Reportes de usuarios distintos que ocultan una reseña
+)
+
+type reviewService struct {
+	reviews  domain.ReviewRepository
+	profiles domain.ProfileRepository
+}
+
+func NewReviewService(reviews domain.ReviewRepository, profiles domain.ProfileRepository) domain.ReviewService {
+	return &reviewService{reviews: reviews, profiles: profiles}
+}
+
+// CreateReview publica la valoración de una cita COMPLETED del propio dueño
+func (s *reviewService) CreateReview(ctx context.Context, authorID, appointmentID uuid.UUID, stars int, body string) (*domain.Review, error) {
+	if stars < 1 || stars > 5 {
+		return nil, fmt.Errorf("%w: la puntuación debe estar entre 1 y 5 estrellas", domain.ErrReviewInvalid)
+	}
+	body = strings.TrimSpace(body)
+	if len([]rune(body)) > reviewMaxLength {
+		return nil, fmt.Errorf("%w: la reseña no puede superar los %d caracteres", domain.ErrReviewInvalid, reviewMaxLength)
+	}
+
+	app, err := s.reviews.GetAppointment(ctx, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if app.OwnerID != authorID || app.Status != domain.AppointmentStatusCompleted {
+		return nil, domain.ErrReviewNotAllowed
+	}
+
+	rv := &domain.Review{
+		ID:            uuid.New(),
+		EntityID:      app.ProfessionalID,
+		AppointmentID: app.ID,
+		AuthorID:      authorID,
+		Stars:         stars,
+		Body:          body,
+		Status:        domain.ReviewStatusPublished,
+	}
+	if err := s.reviews.CreateReview(ctx, rv); err != nil {
+		return nil, err
+	}
+	return s.reviews.GetReview(ctx, rv.ID)
+}
+
+func (s *reviewService) ListReviews(ctx context.Context, profileRef string, limit, offset int) (int, []domain.Review, error) {
+	entityID, err := uuid.Parse(profileRef)
+	if err != nil {
+		id, _, err := s.profiles.ResolveSlug(ctx, slug.Make(profileRef))
+		if err != nil {
+			return 0, nil, err
+		}
+		entityID = uuid.MustParse(id)
+	}
+	return s.reviews.ListPublishedReviews(ctx, entityID, limit, offset)
+}
+
+// Reply publica la respuesta del titular de la ficha valorada
+func (s *reviewService) Reply(ctx context.Context, professionalUserID, reviewID uuid.UUID, reply string) (*domain.Review, error) {
+	reply = strings.TrimSpace(reply)
+	if reply == "" || len([]rune(reply)) > reviewReplyMaxLength {
+		return nil, fmt.Errorf("%w: la respuesta debe tener entre 1 y %d caracteres", domain.ErrReviewInvalid, reviewReplyMaxLength)
+	}
+
+	rv, err := s.reviews.GetReview(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	entity, err := s.profiles.GetProfessionalProfileByUserID(ctx, professionalUserID)
+	if err != nil || entity.ID != rv.EntityID {
+		// Solo el titular responde; para los demás la reseña "no existe"
+		return nil, domain.ErrReviewNotFound
+	}
+
+	if err := s.reviews.SetReply(ctx, reviewID, reply); err != nil {
+		return nil, err
+	}
+	return s.reviews.GetReview(ctx, reviewID)
+}
+
+func (s *reviewService) Report(ctx context.Context, reporterID, reviewID uuid.UUID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("%w: indica el motivo del reporte", domain.ErrReviewInvalid)
+	}
+	if _, err := s.reviews.GetReview(ctx, reviewID); err != nil {
+		return err
+	}
+
+	hidden, err := s.reviews.ReportReview(ctx, reviewID, reporterID, reason, reviewReportThreshold)
+	if err != nil {
+		return err
+	}
+	if hidden {
+		log.Printf("🚩 Reseña %s oculta tras %d reportes, pendiente de moderación (GET /api/admin/reviews)", reviewID, reviewReportThreshold)
+	}
+	return nil
+}
+
+// ListModeration lista las reseñas de un estado; por defecto, las ocultas
+func (s *reviewService) ListModeration(ctx context.Context, status domain.ReviewStatus, limit, offset int) (int, []domain.ReportedReview, error) {
+	switch status {
+	case "":
+		status = domain.ReviewStatusHidden
+	case domain.ReviewStatusHidden, domain.ReviewStatusPublished:
+	default:
+		return 0, nil, domain.ErrReviewStatusInvalid
+	}
+	return s.reviews.ListReviewsByStatus(ctx, status, limit, offset)
+}
+
+func (s *reviewService) Restore(ctx context.Context, adminID, reviewID uuid.UUID) (*domain.Review, error) {
+	return s.moderate(ctx, adminID, reviewID, domain.ReviewStatusPublished)
+}
+
+func (s *reviewService) Hide(ctx context.Context, adminID, reviewID uuid.UUID) (*domain.Review, error) {
+	return s.moderate(ctx, adminID, reviewID, domain.ReviewStatusHidden)
+}
+
+// moderate guarda la decisión del admin; desde ese momento los reportes no la cambian
+func (s *reviewService) moderate(ctx context.Context, adminID, reviewID uuid.UUID, status domain.ReviewStatus) (*domain.Review, error) {
+	if err := s.reviews.ModerateReview(ctx, reviewID, adminID, status); err != nil {
+		return nil, err
+	}
+	log.Printf("🛠️ Admin %s deja la reseña %s en %s", adminID, reviewID, status)
+	return s.reviews.GetReview(ctx, reviewID)
+}
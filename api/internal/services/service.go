@@ -2,27 +2,32 @@ package services
 
 import (
 	"context"
-	"crypto/rand"
-	"fmt"
+	"errors"
 	"log"
 	"time"
-	"math/big"
 	"strings"
 	"veterimap-api/internal/auth"
 	"veterimap-api/internal/domain"
+	"veterimap-api/internal/pkg/mailer"
+	"veterimap-api/internal/pkg/moderation"
+	"veterimap-api/internal/pkg/slug"
 
 	"github.com/google/uuid"
 )
 
 type authService struct {
-	repo domain.UserRepository
+	repo          domain.UserRepository
+	revisions     domain.RevisionRepository
+	sessions      domain.SessionService
+	verifications domain.VerificationRepository
+	mailer        domain.Mailer
 }
 
-func NewAuthService(repo domain.UserRepository) domain.AuthService {
-	return &authService{repo: repo}
+func NewAuthService(repo domain.UserRepository, revisions domain.RevisionRepository, sessions domain.SessionService, verifications domain.VerificationRepository, mailer domain.Mailer) domain.AuthService {
+	return &authService{repo: repo, revisions: revisions, sessions: sessions, verifications: verifications, mailer: mailer}
 }
 
-func (s *authService) Register(ctx context.Context, name, email, password, role, plan string, hasTrial bool) error {
+func (s *authService) Register(ctx context.Context, name, email, password, role, plan string, hasTrial bool, locale string) error {
     cleanEmail := strings.ToLower(strings.TrimSpace(email))
     cleanName := strings.TrimSpace(name)
 
@@ -46,22 +51,16 @@ func (s *authService) Register(ctx context.Context, name, email, password, role,
         }
     }
 
-    // 2. Generación del código (Sin cambios)
-    min := big.NewInt(100000)
-    max := big.NewInt(900000)
-    n, _ := rand.Int(rand.Reader, max)
-    verificationCode := fmt.Sprintf("%06d", new(big.Int).Add(n, min))
-
     u := &domain.User{
         ID:                 uuid.New(),
         Name:               &cleanName,
         Email:              cleanEmail,
         Password:           hashedPassword,
         Role:               domain.Role(role),
-        VerificationCode:   verificationCode,
         IsVerified:         false,
         SubscriptionStatus: subscriptionStatus,
         TrialEndsAt:        trialEndsAt,
+        Locale:             mailer.Locale(locale),
     }
 
     // 3. LLAMADA AL REPOSITORIO (CORREGIDO)
@@ -70,71 +69,178 @@ func (s *authService) Register(ctx context.Context, name, email, password, role,
         return err
     }
 
-    // 4. LOG PARA TERMINAL (Ahora sí se ejecutará)
-    log.Printf("\n==========================================\n")
-    log.Printf("📧 CÓDIGO DE VERIFICACIÓN PARA %s: %s", cleanEmail, verificationCode)
-    log.Printf("💎 PLAN: %s | TRIAL: %v", subscriptionStatus, hasTrial)
-    log.Printf("\n==========================================\n")
+    // 4. Código de verificación por correo (caduca; ver verification.go). Si falla,
+    // la cuenta ya existe y el usuario puede pedir otro desde /api/auth/verify/resend.
+    if err := s.sendVerificationCode(ctx, u.ID, cleanEmail, &cleanName, u.Locale); err != nil {
+        log.Printf("❌ No se pudo enviar el código de verificación a %s: %v", cleanEmail, err)
+    }
+    log.Printf("💎 Nuevo registro %s | PLAN: %s | TRIAL: %v", cleanEmail, subscriptionStatus, hasTrial)
 
     return nil // Finalización exitosa
 }
 
-func (s *authService) Login(ctx context.Context, email, password string) (string, error) {
+// Login valida las credenciales y abre una sesión para el dispositivo
+func (s *authService) Login(ctx context.Context, email, password string, meta domain.SessionMeta) (*domain.TokenPair, error) {
 	u, err := s.repo.GetByEmail(ctx, email)
 	if err != nil {
-		return "", domain.ErrInvalidCredentials
+		return nil, domain.ErrInvalidCredentials
 	}
 
 	if !auth.CheckPasswordHash(password, u.Password) {
-		return "", domain.ErrInvalidCredentials
+		return nil, domain.ErrInvalidCredentials
+	}
+	// Solo tras comprobar la contraseña, para no revelar el estado de cuentas ajenas
+	if !u.IsVerified {
+		return nil, domain.ErrEmailNotVerified
 	}
 
-	return auth.GenerateJWT(u.ID.String(), string(u.Role))
+	return s.sessions.Start(ctx, u, meta)
 }
 
 func (s *authService) GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
 	return s.repo.GetUserByID(ctx, id)
 }
 
-func (s *authService) Verify(ctx context.Context, email, code string) error {
-	u, err := s.repo.GetByEmail(ctx, email)
-	if err != nil {
-		log.Printf("❌ ERROR: No se encontró el email [%s]", email)
-		return err
+// internal/services/service.go
+
+// UpsertProfessionalProfile guarda la ficha del profesional. Horarios, festivos,
+// tarifas y seguros se aplican al momento; los cambios en campos públicos (nombre,
+// bio, colegiado, contacto, direcciones, especialidades) quedan como revisión
+// pendiente y la ficha sigue mostrando la última versión aprobada.
+func (s *authService) UpsertProfessionalProfile(ctx context.Context, userID uuid.UUID, p *domain.ProfessionalEntity) (*domain.ProfileRevision, error) {
+	p.UserID = &userID
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return nil, domain.ErrProfileNameRequired
 	}
 
-	// ESTO NOS DIRÁ LA VERDAD
-	log.Printf("🔍 DEBUG: DB_CODE: '%s' | FRONT_CODE: '%s' | EMAIL: '%s'", u.VerificationCode, code, email)
+	current, err := s.repo.GetProfessionalProfileByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, domain.ErrProfileNotFound) {
+		return nil, err
+	}
 
-	if u.VerificationCode != code {
-		return fmt.Errorf("código incorrecto")
+	// Ficha nueva: se crea oculta (PENDING) y pasa a VERIFIED y visible cuando un
+	// admin aprueba la primera revisión
+	if current == nil {
+		p.Status = domain.EntityStatusPending
+		p.IsActive = false
+		p.Slug = profileSlug(p.Name, p.ProfileData.MainCity())
+		if err := s.repo.UpsertProfessionalProfile(ctx, p); err != nil {
+			return nil, err
+		}
+		return s.submitRevision(ctx, &domain.ProfessionalEntity{}, p)
 	}
-	return s.repo.VerifyUser(ctx, email)
-}
 
-// internal/services/service.go
+	changes := current.ModeratedChanges(p)
+	if len(changes) == 0 {
+		// Si el profesional ha deshecho sus cambios, la revisión pendiente sobra
+		if err := s.revisions.DiscardPendingRevision(ctx, current.ID); err != nil {
+			return nil, err
+		}
+	}
 
-func (s *authService) UpsertProfessionalProfile(ctx context.Context, userID uuid.UUID, p *domain.ProfessionalEntity) error {
-	p.UserID = &userID
+	// Lo que se escribe en la ficha publicada conserva los campos públicos aprobados
+	live := *p
+	live.CopyModerated(current)
+	live.Status = current.Status
+	live.IsActive = current.IsActive
+	live.Slug = profileSlug(live.Name, live.ProfileData.MainCity())
+	if err := s.repo.UpsertProfessionalProfile(ctx, &live); err != nil {
+		return nil, err
+	}
+	p.ID, p.Slug, p.CreatedAt = live.ID, live.Slug, live.CreatedAt
 
-	// Corregimos: "ACTIVE" no existe en tu DB. Usamos "VERIFIED" o "CLAIMED"
-	if p.Status == "" || p.Status == "ACTIVE" {
-		p.Status = "VERIFIED"
+	if len(changes) == 0 {
+		return nil, nil
 	}
+	return s.submitRevision(ctx, current, p)
+}
 
-	p.IsActive = true
+// submitRevision deja en cola los campos públicos de next, con las marcas automáticas
+func (s *authService) submitRevision(ctx context.Context, current, next *domain.ProfessionalEntity) (*domain.ProfileRevision, error) {
+	changes := current.ModeratedChanges(next)
+
+	rev := &domain.ProfileRevision{
+		ID:          uuid.New(),
+		EntityID:    next.ID,
+		UserID:      *next.UserID,
+		Name:        next.Name,
+		EntityType:  next.EntityType,
+		ProfileData: next.ProfileData,
+		Changes:     changes,
+		Flags:       revisionFlags(current, next, changes),
+		Status:      domain.RevisionStatusPending,
+	}
+	if err := s.revisions.CreateRevision(ctx, rev); err != nil {
+		return nil, err
+	}
+
+	if len(rev.Flags) > 0 {
+		log.Printf("🚩 Revisión %s de la ficha %s marcada: %v", rev.ID, rev.EntityID, rev.Flags)
+	}
+	return rev, nil
+}
+
+// revisionFlags revisa los textos libres que han cambiado. Teléfono, email y web ya
+// tienen su campo en contact, así que en la bio o los servicios son sospechosos.
+func revisionFlags(current, next *domain.ProfessionalEntity, changes []string) []string {
+	var texts []string
+	licenseChanged := false
+	for _, field := range changes {
+		switch field {
+		case "name":
+			texts = append(texts, next.Name)
+		case "bio":
+			texts = append(texts, next.ProfileData.Bio)
+		case "specialties":
+			texts = append(texts, next.ProfileData.Specialties...)
+		case "specialization":
+			spec := next.ProfileData.Specialization
+			texts = append(texts, spec.Experience)
+			texts = append(texts, spec.Specialties...)
+			for _, svc := range spec.DetailedServices {
+				texts = append(texts, svc.Name)
+			}
+		case "license_number":
+			// Un colegiado que ya estaba publicado y cambia se comprueba a mano
+			licenseChanged = current.ProfileData.LicenseNumber != ""
+		}
+	}
 
-	// Generar Slug basado en el nombre de forma segura para el puntero
-	if p.Name != "" {
-		generatedSlug := strings.ToLower(strings.ReplaceAll(p.Name, " ", "-"))
-		p.Slug = &generatedSlug
+	flags := moderation.Scan(texts...)
+	if licenseChanged {
+		flags = append(flags, domain.RevisionFlagLicense)
 	}
+	return flags
+}
 
-	// Asegúrate de que esta línea tenga exactamente estos paréntesis:
-	return s.repo.UpsertProfessionalProfile(ctx, p)
+// profileSlug es el slug canónico (nombre + ciudad principal). El repositorio le
+// añade sufijo si ya existe y, si cambia, el anterior queda como redirección.
+func profileSlug(name, city string) *string {
+	if name == "" {
+		return nil
+	}
+	generated := slug.Make(name, city)
+	if generated == "" {
+		generated = slug.Fallback
+	}
+	return &generated
 }
 
+// GetProfessionalProfileByUserID carga la ficha para el modo "Editar" junto con el
+// último envío, para que el profesional vea lo pendiente o el motivo de un rechazo
 func (s *authService) GetProfessionalProfileByUserID(ctx context.Context, userID uuid.UUID) (*domain.ProfessionalEntity, error) {
-	// Este método debe ir al repositorio de perfiles
-	return s.repo.GetProfessionalProfileByUserID(ctx, userID)
+	p, err := s.repo.GetProfessionalProfileByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rev, err := s.revisions.GetLatestRevision(ctx, p.ID)
+	switch {
+	case err == nil:
+		p.Revision = rev
+	case !errors.Is(err, domain.ErrRevisionNotFound):
+		return nil, err
+	}
+	return p, nil
 }
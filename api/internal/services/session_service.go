@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"veterimap-api/internal/auth"
+	"veterimap-api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const (
+	sessionTTL          = 30 * 24 * time.Hour // Sin refrescar en 30 días, la sesión caduca
+	sessionUserAgentMax = 255
+	// Un token reutilizado en este margen desde el mismo user agent e IP se trata como
+	// una carrera entre pestañas del mismo navegador (que comparten localStorage), no
+	// como una filtración
+	refreshReuseGrace = 10 * time.Second
+)
+
+type sessionService struct {
+	sessions domain.SessionRepository
+}
+
+func NewSessionService(sessions domain.SessionRepository) domain.SessionService {
+	return &sessionService{sessions: sessions}
+}
+
+// Start abre una sesión nueva (login) y emite su primer par de tokens
+func (s *sessionService) Start(ctx context.Context, u *domain.User, meta domain.SessionMeta) (*domain.TokenPair, error) {
+	refresh, hash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &domain.Session{
+		ID:        uuid.New(),
+		UserID:    u.ID,
+		Role:      u.Role,
+		UserAgent: truncateRunes(meta.UserAgent, sessionUserAgentMax),
+		IP:        meta.IP,
+		ExpiresAt: time.Now().Add(sessionTTL),
+	}
+	if err := s.sessions.CreateSession(ctx, session, hash); err != nil {
+		return nil, err
+	}
+	return issueTokenPair(session, refresh)
+}
+
+// Refresh consume el refresh token y emite un par nuevo. Cada token vale una sola
+// vez: si vuelve a llegar uno ya usado, se revoca la sesión a la que pertenece (el
+// resto de sesiones del usuario siguen abiertas).
+func (s *sessionService) Refresh(ctx context.Context, refreshToken string, meta domain.SessionMeta) (*domain.TokenPair, error) {
+	refreshToken = strings.TrimSpace(refreshToken)
+	if refreshToken == "" {
+		return nil, domain.ErrRefreshTokenInvalid
+	}
+
+	next, nextHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	meta.UserAgent = truncateRunes(meta.UserAgent, sessionUserAgentMax)
+
+	session, err := s.sessions.RotateRefreshToken(ctx, hashToken(refreshToken), nextHash, meta, time.Now().Add(sessionTTL), refreshReuseGrace)
+	if errors.Is(err, domain.ErrRefreshTokenReused) {
+		log.Printf("🚨 Refresh token reutilizado en la sesión %s del usuario %s (IP %s): sesión revocada", session.ID, session.UserID, meta.IP)
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return issueTokenPair(session, next)
+}
+
+func (s *sessionService) Logout(ctx context.Context, refreshToken string) error {
+	refreshToken = strings.TrimSpace(refreshToken)
+	if refreshToken == "" {
+		return domain.ErrRefreshTokenInvalid
+	}
+	return s.sessions.RevokeSessionByToken(ctx, hashToken(refreshToken), domain.SessionRevokedLogout)
+}
+
+// LogoutAll cierra todas las sesiones del usuario. Los access tokens ya emitidos
+// siguen valiendo hasta que caducan (auth.AccessTokenTTL), pero no se pueden renovar.
+func (s *sessionService) LogoutAll(ctx context.Context, userID uuid.UUID) (int, error) {
+	return s.sessions.RevokeAllSessions(ctx, userID, uuid.Nil, domain.SessionRevokedLogoutAll)
+}
+
+func (s *sessionService) ListSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]domain.Session, error) {
+	sessions, err := s.sessions.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+func (s *sessionService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	return s.sessions.RevokeSession(ctx, userID, sessionID, domain.SessionRevokedByUser)
+}
+
+func issueTokenPair(session *domain.Session, refresh string) (*domain.TokenPair, error) {
+	access, err := auth.GenerateJWT(session.UserID.String(), string(session.Role), session.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int(auth.AccessTokenTTL.Seconds()),
+		SessionID:    session.ID,
+	}, nil
+}
+
+// newOpaqueToken genera un token opaco de 256 bits y el hash que se guarda
+func newOpaqueToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncateRunes(s string, max int) string {
+	if r := []rune(s); len(r) > max {
+		return string(r[:max])
+	}
+	return s
+}
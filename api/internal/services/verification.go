@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"veterimap-api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const (
+	verificationCodeTTL         = 30 * time.Minute
+	verificationMaxAttempts     = 5 // Fallos antes de bloquear el código; hay que pedir otro
+	verificationResendCooldown  = time.Minute
+	verificationMaxSendsPerHour = 5
+)
+
+// Verify comprueba el código de la cuenta. Tras verificationMaxAttempts intentos el
+// código queda bloqueado y solo sirve uno nuevo (ResendVerification).
+func (s *authService) Verify(ctx context.Context, email, code string) error {
+	v, err := s.verifications.GetVerification(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if errors.Is(err, domain.ErrUserNotFound) {
+		// Mismo error que un código incorrecto: no revelamos qué emails existen
+		return domain.ErrVerificationCodeInvalid
+	}
+	if err != nil {
+		return err
+	}
+	if v.IsVerified {
+		return domain.ErrAlreadyVerified
+	}
+
+	// El intento se gasta antes de comparar: el límite se comprueba y se suma a la vez
+	attempt, err := s.verifications.ConsumeVerificationAttempt(ctx, v.UserID, verificationMaxAttempts)
+	if err != nil {
+		return err
+	}
+	if attempt.Code == "" || attempt.ExpiresAt == nil || time.Now().After(*attempt.ExpiresAt) {
+		return domain.ErrVerificationCodeExpired
+	}
+
+	if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(code)), []byte(attempt.Code)) != 1 {
+		if attempt.Attempts >= verificationMaxAttempts {
+			log.Printf("🔒 Código de verificación bloqueado para %s tras %d intentos", v.UserID, attempt.Attempts)
+			return domain.ErrVerificationLocked
+		}
+		return domain.ErrVerificationCodeInvalid
+	}
+
+	// CompleteVerification pone el contador a cero junto con el código
+	if err := s.verifications.CompleteVerification(ctx, v.UserID); err != nil {
+		return err
+	}
+	log.Printf("✅ Cuenta %s verificada", v.UserID)
+	return nil
+}
+
+// ResendVerification envía un código nuevo. Como la recuperación de contraseña, no
+// revela si el email existe o ya está verificado; solo el límite de envíos da error.
+func (s *authService) ResendVerification(ctx context.Context, email string) error {
+	v, err := s.verifications.GetVerification(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if v.IsVerified {
+		return nil
+	}
+
+	now := time.Now()
+	sends, err := s.verifications.RecentVerificationSends(ctx, v.UserID, now.Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if len(sends) > 0 && now.Sub(sends[0]) < verificationResendCooldown {
+		return &domain.VerificationThrottledError{RetryAfter: verificationResendCooldown - now.Sub(sends[0])}
+	}
+	if len(sends) >= verificationMaxSendsPerHour {
+		// Se libera un hueco cuando el envío más antiguo de la ventana cumple una hora
+		return &domain.VerificationThrottledError{RetryAfter: sends[len(sends)-1].Add(time.Hour).Sub(now)}
+	}
+
+	return s.sendVerificationCode(ctx, v.UserID, v.Email, v.Name, v.Locale)
+}
+
+// sendVerificationCode genera un código nuevo (invalida el anterior y reinicia los
+// intentos) y lo envía por correo en el idioma del usuario
+func (s *authService) sendVerificationCode(ctx context.Context, userID uuid.UUID, email string, name *string, locale string) error {
+	code := newNumericCode()
+	if err := s.verifications.SetVerificationCode(ctx, userID, code, time.Now().Add(verificationCodeTTL)); err != nil {
+		return err
+	}
+	return sendVerificationEmail(ctx, s.mailer, email, name, locale, code)
+}
+
+func sendVerificationEmail(ctx context.Context, m domain.Mailer, email string, name *string, locale, code string) error {
+	displayName := ""
+	if name != nil {
+		displayName = *name
+	}
+	return m.Send(ctx, email, locale, domain.EmailVerification, map[string]interface{}{
+		"Name":       displayName,
+		"Code":       code,
+		"TTLMinutes": int(verificationCodeTTL.Minutes()),
+	})
+}
@@ -0,0 +1,82 @@
+-- Esquema base: las tablas que usan los repositorios (db/*_repository.go) tal y
+-- como existían antes de versionar las migraciones. Todo es IF NOT EXISTS para
+-- que una base ya creada a mano quede registrada sin cambios.
+
+CREATE TABLE IF NOT EXISTS users (
+    id                  uuid        PRIMARY KEY DEFAULT gen_random_uuid(),
+    name                text,
+    email               text        NOT NULL UNIQUE,
+    password            text        NOT NULL,
+    role                text        NOT NULL,
+    is_verified         boolean     NOT NULL DEFAULT false,
+    verification_code   text,
+    subscription_status text        NOT NULL DEFAULT 'free',
+    trial_ends_at       timestamptz,
+    slug                text,
+    phone               text,
+    city                text,
+    address             text,
+    postal_code         text,
+    contact_info        jsonb,
+    created_at          timestamptz NOT NULL DEFAULT now(),
+    updated_at          timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS professional_entities (
+    id           uuid        PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      uuid        UNIQUE REFERENCES users(id) ON DELETE SET NULL, -- NULL = ficha importada sin titular
+    entity_type  text        NOT NULL,
+    status       text        NOT NULL DEFAULT 'PROSPECT'
+                             CHECK (status IN ('PROSPECT', 'CLAIMED', 'VERIFIED')),
+    name         text        NOT NULL,
+    slug         text        UNIQUE,
+    profile_data jsonb       NOT NULL DEFAULT '{}',
+    rating       numeric(3, 2) NOT NULL DEFAULT 0,
+    review_count integer     NOT NULL DEFAULT 0,
+    is_active    boolean     NOT NULL DEFAULT true,
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    updated_at   timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS pets (
+    id              uuid        PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id        uuid        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name            text        NOT NULL,
+    species         text,
+    breed           text,
+    birth_date      timestamptz,
+    gender          text,
+    weight          double precision,
+    health_metadata jsonb,
+    created_at      timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_pets_owner ON pets (owner_id);
+
+CREATE TABLE IF NOT EXISTS appointments (
+    id               uuid        PRIMARY KEY DEFAULT gen_random_uuid(),
+    professional_id  uuid        NOT NULL REFERENCES professional_entities(id) ON DELETE CASCADE,
+    owner_id         uuid        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pet_id           uuid        NOT NULL REFERENCES pets(id) ON DELETE CASCADE,
+    appointment_date timestamptz NOT NULL,
+    status           text        NOT NULL DEFAULT 'PENDING', -- PENDING, CONFIRMED, COMPLETED, CANCELLED
+    notes            text,
+    created_at       timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_appointments_professional ON appointments (professional_id, appointment_date);
+CREATE INDEX IF NOT EXISTS idx_appointments_owner ON appointments (owner_id, appointment_date);
+
+CREATE TABLE IF NOT EXISTS medical_histories (
+    id              uuid        PRIMARY KEY DEFAULT gen_random_uuid(),
+    pet_id          uuid        NOT NULL REFERENCES pets(id) ON DELETE CASCADE,
+    professional_id uuid        REFERENCES professional_entities(id) ON DELETE SET NULL,
+    appointment_id  uuid        REFERENCES appointments(id) ON DELETE SET NULL,
+    diagnosis       text,
+    treatment       text,
+    internal_notes  text,
+    created_at      timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_medical_histories_pet ON medical_histories (pet_id, created_at DESC);
+
+-- Sustituye a los índices del antiguo 02_indexes.sql (tablas profiles/locations que ya no existen)
+CREATE INDEX IF NOT EXISTS idx_professional_entities_rating
+    ON professional_entities (rating DESC, review_count DESC);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_professional_entities_geo;
+ALTER TABLE professional_entities
+    DROP COLUMN IF EXISTS geo_lat,
+    DROP COLUMN IF EXISTS geo_lng;
+DROP FUNCTION IF EXISTS haversine_km(double precision, double precision, double precision, double precision);
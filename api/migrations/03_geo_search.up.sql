@@ -0,0 +1,26 @@
+-- Búsqueda geoespacial del mapa (radio y bounding box)
+-- Las coordenadas viven dentro de profile_data->'addresses'; las exponemos como
+-- columnas generadas para poder indexarlas con GiST.
+
+-- 1. Coordenadas de la dirección principal
+ALTER TABLE professional_entities
+    ADD COLUMN IF NOT EXISTS geo_lat double precision
+        GENERATED ALWAYS AS ((profile_data->'addresses'->0->>'latitude')::double precision) STORED,
+    ADD COLUMN IF NOT EXISTS geo_lng double precision
+        GENERATED ALWAYS AS ((profile_data->'addresses'->0->>'longitude')::double precision) STORED;
+
+-- 2. Índice espacial (point = lng, lat) solo sobre fichas activas
+CREATE INDEX IF NOT EXISTS idx_professional_entities_geo
+    ON professional_entities USING gist (point(geo_lng, geo_lat))
+    WHERE is_active = true;
+
+-- 3. Distancia en km (misma fórmula que geo.HaversineKm en Go)
+CREATE OR REPLACE FUNCTION haversine_km(lat1 double precision, lng1 double precision,
+                                        lat2 double precision, lng2 double precision)
+RETURNS double precision
+LANGUAGE sql IMMUTABLE STRICT PARALLEL SAFE AS $$
+    SELECT 6371.0 * 2 * asin(sqrt(
+        power(sin(radians(lat2 - lat1) / 2), 2) +
+        cos(radians(lat1)) * cos(radians(lat2)) * power(sin(radians(lng2 - lng1) / 2), 2)
+    ))
+$$;
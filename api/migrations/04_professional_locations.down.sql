@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS trg_sync_professional_locations ON professional_entities;
+DROP FUNCTION IF EXISTS sync_professional_locations();
+DROP TABLE IF EXISTS professional_locations;
+
+-- Volvemos a las columnas generadas de la primera dirección (estado de la 03)
+ALTER TABLE professional_entities
+    ADD COLUMN IF NOT EXISTS geo_lat double precision
+        GENERATED ALWAYS AS ((profile_data->'addresses'->0->>'latitude')::double precision) STORED,
+    ADD COLUMN IF NOT EXISTS geo_lng double precision
+        GENERATED ALWAYS AS ((profile_data->'addresses'->0->>'longitude')::double precision) STORED;
+CREATE INDEX IF NOT EXISTS idx_professional_entities_geo
+    ON professional_entities USING gist (point(geo_lng, geo_lat))
+    WHERE is_active = true;
@@ -0,0 +1,67 @@
+-- Una fila por cada dirección de profile_data->'addresses'
+-- Sustituye a las columnas geo_lat/geo_lng (solo la primera dirección) de 03_geo_search.sql:
+-- cadenas de clínicas y veterinarios a domicilio aparecen en todas sus ubicaciones.
+
+CREATE TABLE IF NOT EXISTS professional_locations (
+    entity_id     uuid NOT NULL REFERENCES professional_entities(id) ON DELETE CASCADE,
+    address_index integer NOT NULL, -- Posición dentro de profile_data->'addresses'
+    full_address  text NOT NULL DEFAULT '',
+    city          text NOT NULL DEFAULT '',
+    postal_code   text NOT NULL DEFAULT '',
+    latitude      double precision, -- NULL = pendiente de geocodificar
+    longitude     double precision,
+    is_main       boolean NOT NULL DEFAULT false,
+    PRIMARY KEY (entity_id, address_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_professional_locations_geo
+    ON professional_locations USING gist (point(longitude, latitude));
+CREATE INDEX IF NOT EXISTS idx_professional_locations_city
+    ON professional_locations (lower(city));
+
+-- Sincroniza las ubicaciones cada vez que cambia profile_data.
+-- Las coordenadas 0 se guardan como NULL (así las detecta el geocodificador) y,
+-- si ninguna dirección está marcada como principal, la primera hace de principal.
+CREATE OR REPLACE FUNCTION sync_professional_locations() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    DELETE FROM professional_locations WHERE entity_id = NEW.id;
+
+    INSERT INTO professional_locations (
+        entity_id, address_index, full_address, city, postal_code, latitude, longitude, is_main
+    )
+    SELECT
+        NEW.id,
+        a.ord - 1,
+        COALESCE(a.addr->>'full_address', ''),
+        COALESCE(a.addr->>'city', ''),
+        COALESCE(a.addr->>'postal_code', ''),
+        CASE WHEN jsonb_typeof(a.addr->'latitude') = 'number'
+             THEN NULLIF((a.addr->>'latitude')::double precision, 0) END,
+        CASE WHEN jsonb_typeof(a.addr->'longitude') = 'number'
+             THEN NULLIF((a.addr->>'longitude')::double precision, 0) END,
+        COALESCE(a.addr->>'is_main' = 'true', false)
+            OR (a.ord = 1 AND NOT bool_or(COALESCE(a.addr->>'is_main' = 'true', false)) OVER ())
+    FROM jsonb_array_elements(
+        CASE WHEN jsonb_typeof(NEW.profile_data->'addresses') = 'array'
+             THEN NEW.profile_data->'addresses' ELSE '[]'::jsonb END
+    ) WITH ORDINALITY AS a(addr, ord)
+    WHERE jsonb_typeof(a.addr) = 'object';
+
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_sync_professional_locations ON professional_entities;
+CREATE TRIGGER trg_sync_professional_locations
+    AFTER INSERT OR UPDATE OF profile_data ON professional_entities
+    FOR EACH ROW EXECUTE FUNCTION sync_professional_locations();
+
+-- Relleno inicial: forzamos el trigger sobre las filas existentes
+UPDATE professional_entities SET profile_data = profile_data;
+
+-- Las columnas generadas de la primera dirección ya no se usan
+DROP INDEX IF EXISTS idx_professional_entities_geo;
+ALTER TABLE professional_entities
+    DROP COLUMN IF EXISTS geo_lat,
+    DROP COLUMN IF EXISTS geo_lng;
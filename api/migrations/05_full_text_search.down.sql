@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_professional_entities_search;
+DROP TRIGGER IF EXISTS trg_professional_search_vector ON professional_entities;
+DROP FUNCTION IF EXISTS update_professional_search_vector();
+ALTER TABLE professional_entities DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS professional_search_vector(text, jsonb);
+DROP FUNCTION IF EXISTS jsonb_path_text(jsonb, jsonpath);
+DROP TEXT SEARCH CONFIGURATION IF EXISTS es_unaccent;
+DROP FUNCTION IF EXISTS immutable_unaccent(text);
+-- La extensión unaccent se deja instalada: es compartida
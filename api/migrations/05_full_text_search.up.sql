@@ -0,0 +1,65 @@
+-- Búsqueda de texto completo insensible a tildes
+-- "sanchez" encuentra "Veterinaria Sánchez"; se busca en nombre, especialidades,
+-- servicios, ciudades y bio con pesos distintos (A > B > C > D).
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- unaccent() es STABLE; fijando el diccionario podemos usarla en índices y columnas
+CREATE OR REPLACE FUNCTION immutable_unaccent(text) RETURNS text
+LANGUAGE sql IMMUTABLE STRICT PARALLEL SAFE AS $$
+    SELECT public.unaccent('public.unaccent'::regdictionary, $1)
+$$;
+
+-- Configuración española que elimina tildes antes del stemming
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'es_unaccent') THEN
+        CREATE TEXT SEARCH CONFIGURATION es_unaccent (COPY = spanish);
+        ALTER TEXT SEARCH CONFIGURATION es_unaccent
+            ALTER MAPPING FOR hword, hword_part, word WITH unaccent, spanish_stem;
+    END IF;
+END
+$$;
+
+-- Concatena los textos de una ruta jsonpath (modo lax: ignora rutas ausentes o mal tipadas)
+CREATE OR REPLACE FUNCTION jsonb_path_text(data jsonb, path jsonpath) RETURNS text
+LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT COALESCE(string_agg(v #>> '{}', ' '), '')
+    FROM jsonb_path_query(COALESCE(data, '{}'::jsonb), path) AS v
+    WHERE jsonb_typeof(v) = 'string'
+$$;
+
+CREATE OR REPLACE FUNCTION professional_search_vector(p_name text, p_data jsonb) RETURNS tsvector
+LANGUAGE sql STABLE PARALLEL SAFE AS $$
+    SELECT
+        setweight(to_tsvector('es_unaccent', COALESCE(p_name, '')), 'A') ||
+        setweight(to_tsvector('es_unaccent',
+            jsonb_path_text(p_data, 'lax $.specialties[*]') || ' ' ||
+            jsonb_path_text(p_data, 'lax $.specialization.specialties[*]')), 'B') ||
+        setweight(to_tsvector('es_unaccent',
+            jsonb_path_text(p_data, 'lax $.specialization.detailed_services[*].name') || ' ' ||
+            jsonb_path_text(p_data, 'lax $.pricing.tarifas[*].name')), 'B') ||
+        setweight(to_tsvector('es_unaccent', jsonb_path_text(p_data, 'lax $.addresses[*].city')), 'C') ||
+        setweight(to_tsvector('es_unaccent', jsonb_path_text(p_data, 'lax $.bio')), 'D')
+$$;
+
+ALTER TABLE professional_entities ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION update_professional_search_vector() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.search_vector := professional_search_vector(NEW.name, NEW.profile_data);
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_professional_search_vector ON professional_entities;
+CREATE TRIGGER trg_professional_search_vector
+    BEFORE INSERT OR UPDATE OF name, profile_data ON professional_entities
+    FOR EACH ROW EXECUTE FUNCTION update_professional_search_vector();
+
+-- Relleno inicial
+UPDATE professional_entities SET search_vector = professional_search_vector(name, profile_data);
+
+CREATE INDEX IF NOT EXISTS idx_professional_entities_search
+    ON professional_entities USING gin (search_vector);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_professional_locations_city_prefix;
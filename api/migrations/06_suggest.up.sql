@@ -0,0 +1,6 @@
+-- Autocompletado (/api/profiles/suggest)
+-- Los nombres se resuelven con search_vector (peso A); ciudades y especialidades
+-- se comparan por prefijo sin tildes ni mayúsculas.
+
+CREATE INDEX IF NOT EXISTS idx_professional_locations_city_prefix
+    ON professional_locations (lower(immutable_unaccent(city)) text_pattern_ops);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trg_sync_professional_schedule ON professional_entities;
+DROP FUNCTION IF EXISTS sync_professional_schedule();
+DROP TABLE IF EXISTS professional_holidays;
+DROP TABLE IF EXISTS professional_opening_hours;
@@ -0,0 +1,92 @@
+-- Filtro "abierto ahora" / "abierto a las..."
+-- profile_data->'working_hours' se normaliza en tramos (día ISO, apertura, cierre)
+-- para filtrar en SQL sin perder el conteo ni la paginación. Misma semántica que
+-- domain.Schedule.IsOpenAt: turnos partidos, turnos que cruzan la medianoche y
+-- festivos que cierran el día natural completo.
+
+CREATE TABLE IF NOT EXISTS professional_opening_hours (
+    entity_id uuid     NOT NULL REFERENCES professional_entities(id) ON DELETE CASCADE,
+    weekday   smallint NOT NULL CHECK (weekday BETWEEN 1 AND 7), -- ISO: 1 = lunes
+    opens     time     NOT NULL,
+    closes    time     NOT NULL -- '24:00' = hasta medianoche
+);
+CREATE INDEX IF NOT EXISTS idx_professional_opening_hours
+    ON professional_opening_hours (weekday, opens, closes, entity_id);
+
+CREATE TABLE IF NOT EXISTS professional_holidays (
+    entity_id uuid NOT NULL REFERENCES professional_entities(id) ON DELETE CASCADE,
+    day       date NOT NULL,
+    PRIMARY KEY (entity_id, day)
+);
+
+CREATE OR REPLACE FUNCTION sync_professional_schedule() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    d       record;
+    sh      record;
+    h       jsonb;
+    dow     smallint;
+    t_open  time;
+    t_close time;
+    clock   constant text := '^(([01]?[0-9]|2[0-3]):[0-5][0-9]|24:00)$';
+BEGIN
+    DELETE FROM professional_opening_hours WHERE entity_id = NEW.id;
+    DELETE FROM professional_holidays WHERE entity_id = NEW.id;
+
+    IF jsonb_typeof(NEW.profile_data->'working_hours') = 'object' THEN
+        FOR d IN SELECT key, value FROM jsonb_each(NEW.profile_data->'working_hours') LOOP
+            dow := CASE lower(d.key)
+                WHEN 'monday' THEN 1 WHEN 'tuesday' THEN 2 WHEN 'wednesday' THEN 3
+                WHEN 'thursday' THEN 4 WHEN 'friday' THEN 5 WHEN 'saturday' THEN 6
+                WHEN 'sunday' THEN 7 END;
+            CONTINUE WHEN dow IS NULL OR jsonb_typeof(d.value) <> 'object'
+                OR COALESCE(d.value->>'active', 'false') <> 'true';
+
+            FOR sh IN
+                SELECT value FROM jsonb_array_elements(
+                    CASE WHEN jsonb_typeof(d.value->'shifts') = 'array' AND jsonb_array_length(d.value->'shifts') > 0
+                         THEN d.value->'shifts'
+                         ELSE jsonb_build_array(jsonb_build_object('start', d.value->'start', 'end', d.value->'end'))
+                    END)
+            LOOP
+                CONTINUE WHEN COALESCE(sh.value->>'start', '') !~ clock
+                    OR COALESCE(sh.value->>'end', '') !~ clock;
+
+                t_open := (sh.value->>'start')::time;
+                t_close := (sh.value->>'end')::time;
+
+                IF t_close > t_open THEN
+                    INSERT INTO professional_opening_hours VALUES (NEW.id, dow, t_open, t_close);
+                ELSE
+                    -- Turno nocturno: hasta medianoche hoy y desde medianoche mañana
+                    INSERT INTO professional_opening_hours VALUES (NEW.id, dow, t_open, '24:00');
+                    IF t_close > '00:00' THEN
+                        INSERT INTO professional_opening_hours VALUES (NEW.id, dow % 7 + 1, '00:00', t_close);
+                    END IF;
+                END IF;
+            END LOOP;
+        END LOOP;
+    END IF;
+
+    IF jsonb_typeof(NEW.profile_data->'holidays') = 'array' THEN
+        FOR h IN SELECT value FROM jsonb_array_elements(NEW.profile_data->'holidays') LOOP
+            BEGIN
+                INSERT INTO professional_holidays VALUES (NEW.id, (h->>'date')::date)
+                ON CONFLICT DO NOTHING;
+            EXCEPTION WHEN others THEN
+                NULL; -- Fecha ilegible: se ignora igual que en Go
+            END;
+        END LOOP;
+    END IF;
+
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_sync_professional_schedule ON professional_entities;
+CREATE TRIGGER trg_sync_professional_schedule
+    AFTER INSERT OR UPDATE OF profile_data ON professional_entities
+    FOR EACH ROW EXECUTE FUNCTION sync_professional_schedule();
+
+-- Relleno inicial (también vuelve a sincronizar ubicaciones y search_vector, es idempotente)
+UPDATE professional_entities SET profile_data = profile_data;
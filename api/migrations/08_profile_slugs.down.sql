@@ -0,0 +1,6 @@
+-- Los slugs ya normalizados se quedan; solo desaparecen las redirecciones
+DROP TRIGGER IF EXISTS trg_professional_slug_history ON professional_entities;
+DROP FUNCTION IF EXISTS sync_professional_slug_history();
+DROP FUNCTION IF EXISTS unique_slug(text, uuid);
+DROP TABLE IF EXISTS professional_slug_history;
+DROP FUNCTION IF EXISTS slugify(text);
@@ -0,0 +1,82 @@
+-- URLs públicas por slug: /api/profiles/{slug}
+-- El slug canónico es nombre + ciudad principal, sin tildes y en minúsculas
+-- ("clinica-sol-madrid"); los repetidos se numeran ("clinica-sol-madrid-2").
+-- Cuando un perfil cambia de slug, el anterior se guarda en el histórico para
+-- responder con un 301 al actual.
+
+-- Misma regla que slug.Make en Go
+CREATE OR REPLACE FUNCTION slugify(text) RETURNS text
+LANGUAGE sql IMMUTABLE STRICT PARALLEL SAFE AS $$
+    SELECT trim(both '-' from regexp_replace(lower(immutable_unaccent($1)), '[^a-z0-9]+', '-', 'g'))
+$$;
+
+CREATE TABLE IF NOT EXISTS professional_slug_history (
+    slug       text        PRIMARY KEY,
+    entity_id  uuid        NOT NULL REFERENCES professional_entities(id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_professional_slug_history_entity ON professional_slug_history (entity_id);
+
+-- unique_slug devuelve base o base-N libre. Un slug está ocupado si es el actual de
+-- otro perfil o una redirección de otro perfil; los del propio perfil (self) se reutilizan.
+CREATE OR REPLACE FUNCTION unique_slug(base text, self uuid) RETURNS text
+LANGUAGE plpgsql STABLE AS $$
+DECLARE
+    candidate text := base;
+    n         int  := 1;
+BEGIN
+    LOOP
+        EXIT WHEN NOT EXISTS (
+                SELECT 1 FROM professional_entities WHERE slug = candidate AND id IS DISTINCT FROM self)
+            AND NOT EXISTS (
+                SELECT 1 FROM professional_slug_history WHERE slug = candidate AND entity_id IS DISTINCT FROM self);
+        n := n + 1;
+        candidate := base || '-' || n;
+    END LOOP;
+    RETURN candidate;
+END;
+$$;
+
+-- Al cambiar de slug, el anterior pasa a ser redirección y el nuevo deja de serlo
+CREATE OR REPLACE FUNCTION sync_professional_slug_history() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF COALESCE(OLD.slug, '') <> '' THEN
+        INSERT INTO professional_slug_history (slug, entity_id)
+        VALUES (OLD.slug, NEW.id)
+        ON CONFLICT (slug) DO UPDATE SET entity_id = EXCLUDED.entity_id, created_at = now();
+    END IF;
+    DELETE FROM professional_slug_history WHERE slug = NEW.slug;
+    RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_professional_slug_history ON professional_entities;
+CREATE TRIGGER trg_professional_slug_history
+    AFTER UPDATE OF slug ON professional_entities
+    FOR EACH ROW
+    WHEN (OLD.slug IS DISTINCT FROM NEW.slug)
+    EXECUTE FUNCTION sync_professional_slug_history();
+
+-- Backfill: normaliza los slugs vacíos o generados con las reglas antiguas
+-- (espacios por guiones, tildes convertidas en guiones...). Fila a fila para que
+-- unique_slug vea los slugs que se van asignando.
+DO $$
+DECLARE
+    rec record;
+BEGIN
+    FOR rec IN
+        SELECT e.id, e.slug,
+               COALESCE(NULLIF(slugify(concat_ws(' ', e.name, (
+                   SELECT l.city FROM professional_locations l
+                   WHERE l.entity_id = e.id
+                   ORDER BY l.is_main DESC, l.address_index
+                   LIMIT 1))), ''), 'profesional') AS base
+        FROM professional_entities e
+        ORDER BY e.created_at, e.id
+    LOOP
+        CONTINUE WHEN rec.slug = rec.base OR rec.slug ~ ('^' || rec.base || '-[0-9]+$');
+        UPDATE professional_entities SET slug = unique_slug(rec.base, rec.id) WHERE id = rec.id;
+    END LOOP;
+END;
+$$;
@@ -0,0 +1,28 @@
+-- Reclamación de fichas: un profesional registrado toma el control de una ficha
+-- PROSPECT importada (sin user_id). Demuestra que es suya con un código enviado al
+-- email o teléfono de la ficha, o aportando pruebas para que un admin la apruebe.
+
+CREATE TABLE IF NOT EXISTS profile_claims (
+    id          uuid        PRIMARY KEY,
+    entity_id   uuid        NOT NULL REFERENCES professional_entities(id) ON DELETE CASCADE,
+    user_id     uuid        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    method      text        NOT NULL CHECK (method IN ('EMAIL', 'PHONE', 'ADMIN')),
+    status      text        NOT NULL DEFAULT 'PENDING'
+                            CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED', 'CANCELLED')),
+    destination text        NOT NULL DEFAULT '', -- Email o teléfono al que se envió el código
+    code_hash   text        NOT NULL DEFAULT '', -- bcrypt del código (vacío en método ADMIN)
+    attempts    int         NOT NULL DEFAULT 0,
+    expires_at  timestamptz,
+    evidence    text        NOT NULL DEFAULT '', -- Pruebas aportadas para la revisión manual
+    review_note text        NOT NULL DEFAULT '',
+    reviewed_by uuid        REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at timestamptz,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+-- Una sola reclamación abierta por profesional y ficha
+CREATE UNIQUE INDEX IF NOT EXISTS idx_profile_claims_open
+    ON profile_claims (entity_id, user_id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_profile_claims_status ON profile_claims (status, created_at);
+CREATE INDEX IF NOT EXISTS idx_profile_claims_user ON profile_claims (user_id, created_at DESC);
@@ -0,0 +1,9 @@
+-- El rating vuelve a ser el importado
+UPDATE professional_entities SET rating = base_rating, review_count = base_review_count;
+
+DROP FUNCTION IF EXISTS refresh_entity_rating(uuid);
+DROP TABLE IF EXISTS review_reports;
+DROP TABLE IF EXISTS reviews;
+ALTER TABLE professional_entities
+    DROP COLUMN IF EXISTS base_rating,
+    DROP COLUMN IF EXISTS base_review_count;
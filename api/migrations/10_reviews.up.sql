@@ -0,0 +1,62 @@
+-- Reseñas de dueños de mascotas tras una cita COMPLETED (una por cita).
+-- rating/review_count de professional_entities pasan a ser un agregado: la valoración
+-- importada (base_rating/base_review_count, p. ej. Google) más las reseñas publicadas.
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'professional_entities' AND column_name = 'base_rating'
+    ) THEN
+        ALTER TABLE professional_entities
+            ADD COLUMN base_rating       double precision NOT NULL DEFAULT 0,
+            ADD COLUMN base_review_count integer          NOT NULL DEFAULT 0;
+        -- Solo la primera vez: hasta ahora rating/review_count eran los importados
+        UPDATE professional_entities
+        SET base_rating = COALESCE(rating, 0), base_review_count = COALESCE(review_count, 0);
+    END IF;
+END;
+$$;
+
+CREATE TABLE IF NOT EXISTS reviews (
+    id             uuid        PRIMARY KEY,
+    entity_id      uuid        NOT NULL REFERENCES professional_entities(id) ON DELETE CASCADE,
+    appointment_id uuid        NOT NULL UNIQUE REFERENCES appointments(id) ON DELETE CASCADE,
+    author_id      uuid        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    stars          smallint    NOT NULL CHECK (stars BETWEEN 1 AND 5),
+    body           text        NOT NULL DEFAULT '',
+    status         text        NOT NULL DEFAULT 'PUBLISHED' CHECK (status IN ('PUBLISHED', 'HIDDEN')),
+    reply          text,
+    replied_at     timestamptz,
+    report_count   integer     NOT NULL DEFAULT 0,
+    created_at     timestamptz NOT NULL DEFAULT now(),
+    updated_at     timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_reviews_entity_published
+    ON reviews (entity_id, created_at DESC) WHERE status = 'PUBLISHED';
+
+-- Un reporte por usuario y reseña
+CREATE TABLE IF NOT EXISTS review_reports (
+    review_id   uuid        NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    reporter_id uuid        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason      text        NOT NULL,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (review_id, reporter_id)
+);
+
+-- Recalcula el agregado de una ficha. Se llama dentro de la misma transacción que
+-- crea, oculta o reimporta, así el listado nunca ve una valoración a medias.
+CREATE OR REPLACE FUNCTION refresh_entity_rating(entity uuid) RETURNS void
+LANGUAGE sql AS $$
+    UPDATE professional_entities e
+    SET rating = CASE WHEN e.base_review_count + agg.n = 0 THEN 0
+                      ELSE round(((e.base_rating * e.base_review_count + agg.total)
+                                  / (e.base_review_count + agg.n))::numeric, 2) END,
+        review_count = e.base_review_count + agg.n
+    FROM (
+        SELECT COUNT(*) AS n, COALESCE(SUM(stars), 0) AS total
+        FROM reviews
+        WHERE entity_id = entity AND status = 'PUBLISHED'
+    ) agg
+    WHERE e.id = entity
+$$;
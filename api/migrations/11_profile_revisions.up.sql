@@ -0,0 +1,25 @@
+-- Moderación de fichas: los cambios en campos públicos (nombre, bio, colegiado,
+-- contacto, direcciones, especialidades) se guardan como revisión pendiente y la
+-- versión aprobada sigue visible hasta que un admin la acepta o rechaza.
+
+CREATE TABLE IF NOT EXISTS profile_revisions (
+    id           uuid        PRIMARY KEY,
+    entity_id    uuid        NOT NULL REFERENCES professional_entities(id) ON DELETE CASCADE,
+    user_id      uuid        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name         text        NOT NULL,
+    entity_type  text        NOT NULL,
+    profile_data jsonb       NOT NULL,
+    changes      text[]      NOT NULL DEFAULT '{}', -- Campos públicos que difieren de la versión aprobada
+    flags        text[]      NOT NULL DEFAULT '{}', -- Marcas automáticas: link, phone, email, profanity, license_number
+    status       text        NOT NULL DEFAULT 'PENDING'
+                             CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED', 'SUPERSEDED')),
+    reason       text        NOT NULL DEFAULT '', -- Motivo del rechazo, visible para el profesional
+    reviewed_by  uuid        REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at  timestamptz,
+    created_at   timestamptz NOT NULL DEFAULT now()
+);
+
+-- Como mucho una revisión pendiente por ficha: la nueva sustituye a la anterior
+CREATE UNIQUE INDEX IF NOT EXISTS idx_profile_revisions_pending
+    ON profile_revisions (entity_id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_profile_revisions_status ON profile_revisions (status, created_at);
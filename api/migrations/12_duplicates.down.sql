@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS entity_merges;
+DROP TABLE IF EXISTS duplicate_dismissals;
+DROP INDEX IF EXISTS idx_professional_entities_phone;
+DROP FUNCTION IF EXISTS normalized_phone(text);
+DROP FUNCTION IF EXISTS normalized_name(text);
+-- pg_trgm se deja instalada: es una extensión compartida
@@ -0,0 +1,43 @@
+-- Detección y fusión de fichas duplicadas (misma clínica importada con otra
+-- grafía, o profesional registrado + su ficha PROSPECT importada).
+-- Una pareja es candidata si comparte teléfono o tiene direcciones a menos de
+-- 500 m; la puntuación combina además la similitud del nombre (pg_trgm).
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Nombre normalizado para comparar: minúsculas y sin tildes
+CREATE OR REPLACE FUNCTION normalized_name(text) RETURNS text
+LANGUAGE sql IMMUTABLE STRICT PARALLEL SAFE AS $$
+    SELECT lower(immutable_unaccent($1))
+$$;
+
+-- Teléfono comparable: solo los 9 últimos dígitos (sin prefijo +34 ni separadores)
+CREATE OR REPLACE FUNCTION normalized_phone(text) RETURNS text
+LANGUAGE sql IMMUTABLE STRICT PARALLEL SAFE AS $$
+    SELECT NULLIF(right(regexp_replace($1, '\D', '', 'g'), 9), '')
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_professional_entities_phone
+    ON professional_entities (normalized_phone(profile_data->'contact'->>'phone'));
+
+-- Parejas que un admin ha revisado y no son duplicados (entity_a < entity_b)
+CREATE TABLE IF NOT EXISTS duplicate_dismissals (
+    entity_a     uuid        NOT NULL REFERENCES professional_entities(id) ON DELETE CASCADE,
+    entity_b     uuid        NOT NULL REFERENCES professional_entities(id) ON DELETE CASCADE,
+    dismissed_by uuid        REFERENCES users(id) ON DELETE SET NULL,
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (entity_a, entity_b),
+    CHECK (entity_a < entity_b)
+);
+
+-- Registro de fusiones: la ficha absorbida se borra, aquí queda su foto completa
+CREATE TABLE IF NOT EXISTS entity_merges (
+    id          uuid        PRIMARY KEY,
+    survivor_id uuid        REFERENCES professional_entities(id) ON DELETE SET NULL,
+    merged_id   uuid        NOT NULL, -- Ya no existe en professional_entities
+    merged_slug text,
+    snapshot    jsonb       NOT NULL, -- Fila completa de la ficha absorbida
+    merged_by   uuid        REFERENCES users(id) ON DELETE SET NULL,
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_entity_merges_survivor ON entity_merges (survivor_id);
@@ -0,0 +1,3 @@
+ALTER TABLE professional_entities
+    DROP COLUMN IF EXISTS import_source,
+    DROP COLUMN IF EXISTS imported_at;
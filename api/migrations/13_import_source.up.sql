@@ -0,0 +1,4 @@
+-- Procedencia de las fichas importadas (cmd/tools/importar --source)
+ALTER TABLE professional_entities
+    ADD COLUMN IF NOT EXISTS import_source text,
+    ADD COLUMN IF NOT EXISTS imported_at   timestamptz;
@@ -0,0 +1,27 @@
+-- Sesiones con refresh token rotatorio. Cada login abre una sesión (un
+-- dispositivo); cada refresh consume el token y emite otro. Si llega un token
+-- ya consumido, alguien lo ha copiado: se revoca la sesión entera.
+CREATE TABLE IF NOT EXISTS sessions (
+    id             uuid        PRIMARY KEY,
+    user_id        uuid        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent     text        NOT NULL DEFAULT '',
+    ip             text        NOT NULL DEFAULT '',
+    created_at     timestamptz NOT NULL DEFAULT now(),
+    last_seen_at   timestamptz NOT NULL DEFAULT now(),
+    expires_at     timestamptz NOT NULL,
+    revoked_at     timestamptz,
+    revoked_reason text -- logout, logout_all, revoked, reuse
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_active
+    ON sessions (user_id, last_seen_at DESC) WHERE revoked_at IS NULL;
+
+-- Solo se guarda el SHA-256 del token: una fuga de la tabla no permite usarlos
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    token_hash text        PRIMARY KEY,
+    session_id uuid        NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    used_at    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session ON refresh_tokens (session_id);
@@ -0,0 +1,14 @@
+-- Recuperación de contraseña: enlaces de un solo uso que caducan. Como en
+-- refresh_tokens, solo se guarda el SHA-256 del token.
+CREATE TABLE IF NOT EXISTS password_resets (
+    token_hash text        PRIMARY KEY,
+    user_id    uuid        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ip         text        NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    expires_at timestamptz NOT NULL,
+    used_at    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_resets_user ON password_resets (user_id, created_at DESC);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at timestamptz;
@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN IF EXISTS trial_notice_sent_at;
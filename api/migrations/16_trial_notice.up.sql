@@ -0,0 +1,3 @@
+-- Aviso de fin de prueba: se marca al enviarlo para que cmd/tools/avisar_fin_prueba
+-- no lo repita. Si el admin cambia trial_ends_at, el aviso se vuelve a enviar.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS trial_notice_sent_at timestamptz;
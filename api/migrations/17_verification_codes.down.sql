@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS verification_sends;
+ALTER TABLE users DROP COLUMN IF EXISTS verification_attempts;
+ALTER TABLE users DROP COLUMN IF EXISTS verification_expires_at;
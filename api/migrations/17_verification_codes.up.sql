@@ -0,0 +1,18 @@
+-- Códigos de verificación de cuenta: caducan, admiten un número limitado de
+-- intentos y cada envío queda registrado para limitar los reenvíos.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_expires_at timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_attempts integer NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS verification_sends (
+    user_id uuid        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    sent_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_verification_sends_user ON verification_sends (user_id, sent_at DESC);
+
+-- Los códigos pendientes de antes no caducaban: se les da un día de margen
+UPDATE users SET verification_expires_at = NOW() + interval '1 day'
+WHERE NOT is_verified AND verification_code IS NOT NULL;
+
+-- En las cuentas ya verificadas el código sobra
+UPDATE users SET verification_code = NULL WHERE is_verified;
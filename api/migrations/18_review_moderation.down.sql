@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_reviews_status;
+ALTER TABLE reviews DROP COLUMN IF EXISTS moderated_at;
+ALTER TABLE reviews DROP COLUMN IF EXISTS moderated_by;
@@ -0,0 +1,6 @@
+-- Moderación de reseñas ocultas por reportes. Una vez que un admin decide (restaurar
+-- o confirmar la ocultación), los reportes posteriores ya no la ocultan solos.
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS moderated_by uuid REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS moderated_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS idx_reviews_status ON reviews (status, updated_at DESC);
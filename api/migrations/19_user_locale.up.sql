@@ -0,0 +1,3 @@
+-- Idioma de los correos de cada usuario. Se toma de Accept-Language al registrarse;
+-- las cuentas anteriores se quedan en español, el idioma por defecto de las plantillas.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale text NOT NULL DEFAULT 'es';
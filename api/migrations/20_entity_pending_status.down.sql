@@ -0,0 +1,4 @@
+UPDATE professional_entities SET status = 'VERIFIED' WHERE status = 'PENDING';
+ALTER TABLE professional_entities DROP CONSTRAINT IF EXISTS professional_entities_status_check;
+ALTER TABLE professional_entities ADD CONSTRAINT professional_entities_status_check
+    CHECK (status IN ('PROSPECT', 'CLAIMED', 'VERIFIED'));
@@ -0,0 +1,12 @@
+-- PENDING: ficha creada por el propio profesional que espera la primera aprobación.
+-- Pasa a VERIFIED cuando un admin aprueba esa revisión.
+ALTER TABLE professional_entities DROP CONSTRAINT IF EXISTS professional_entities_status_check;
+ALTER TABLE professional_entities ADD CONSTRAINT professional_entities_status_check
+    CHECK (status IN ('PROSPECT', 'PENDING', 'CLAIMED', 'VERIFIED'));
+
+-- Las que se crearon como VERIFIED y siguen sin ninguna revisión aprobada
+UPDATE professional_entities e
+SET status = 'PENDING'
+WHERE e.status = 'VERIFIED' AND NOT e.is_active
+  AND EXISTS (SELECT 1 FROM profile_revisions r WHERE r.entity_id = e.id AND r.status = 'PENDING')
+  AND NOT EXISTS (SELECT 1 FROM profile_revisions r WHERE r.entity_id = e.id AND r.status = 'APPROVED');
@@ -0,0 +1,11 @@
+// Package migrations contiene las migraciones SQL versionadas del esquema,
+// embebidas en el binario de la API.
+//
+// Cada versión es NN_nombre.up.sql y, opcionalmente, NN_nombre.down.sql para
+// deshacerla. Sin .down.sql la migración es irreversible.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
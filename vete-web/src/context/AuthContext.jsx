@@ -1,4 +1,5 @@
 import React, { createContext, useState, useContext, useEffect } from 'react';
+import api from '../services/api';
 
 export const AuthContext = createContext(null);
 
@@ -65,6 +66,10 @@ const checkProfileStatus = async () => {
                 // --------------------------
 
             } else if (response.status === 401) {
+                // El access token dura poco: se intenta renovar antes de cerrar sesión
+                if (await api.refreshSession()) {
+                    return checkProfileStatus();
+                }
                 logout();
             }
         } catch (error) {
@@ -80,8 +85,9 @@ const checkProfileStatus = async () => {
         // eslint-disable-next-line react-hooks/exhaustive-deps
     }, []); // Desactivamos el aviso aquí para evitar bucles y que el linter no se queje
 
-    const login = async (token) => {
+    const login = async (token, refreshToken) => {
         localStorage.setItem('token', token);
+        if (refreshToken) localStorage.setItem('refresh_token', refreshToken);
         const payload = decodeJWT(token);
         const userData = { id: payload.user_id, role: payload.role, token, ...payload };
         setUser(userData);
@@ -90,7 +96,10 @@ const checkProfileStatus = async () => {
     };
 
     const logout = () => {
+        const refreshToken = localStorage.getItem('refresh_token');
+        if (refreshToken) api.logout(refreshToken).catch(() => {}); // Cierra la sesión en el servidor
         localStorage.removeItem('token');
+        localStorage.removeItem('refresh_token');
         setUser(null);
     };
 
@@ -22,7 +22,7 @@ const Login = () => {
       if (!token) throw new Error("Credenciales inválidas o cuenta no verificada");
 
       // 2. Guardar token en contexto
-      await login(token); 
+      await login(token, loginRes.refresh_token); 
       
       // 3. Pedir los datos frescos del perfil
       const profileRes = await api.getOwnProfile();
@@ -60,6 +60,12 @@ const Login = () => {
       }
 
     } catch (loginErr) {
+      // Cuenta sin verificar: a la pantalla del código, con el email ya puesto
+      if (loginErr.code === 'EMAIL_NOT_VERIFIED') {
+        localStorage.setItem('pendingEmail', email.trim().toLowerCase());
+        navigate('/verify');
+        return;
+      }
       console.error("Fallo en la autenticación:", loginErr.message);
       alert(loginErr.message || "Error al iniciar sesión.");
     } finally {
@@ -7,8 +7,16 @@ const Verify = () => {
   const [email, setEmail] = useState('');
   const [message, setMessage] = useState({ text: '', isError: false });
   const [loading, setLoading] = useState(false);
+  const [resendIn, setResendIn] = useState(0);
   const navigate = useNavigate();
 
+  // Cuenta atrás del botón de reenvío (el servidor indica la espera en Retry-After)
+  useEffect(() => {
+    if (resendIn <= 0) return;
+    const timer = setTimeout(() => setResendIn(resendIn - 1), 1000);
+    return () => clearTimeout(timer);
+  }, [resendIn]);
+
   useEffect(() => {
     const pendingEmail = localStorage.getItem('pendingEmail');
     if (!pendingEmail) {
@@ -28,10 +36,7 @@ const Verify = () => {
     setMessage({ text: '', isError: false });
 
     try {
-      await api.request('/auth/verify', {
-        method: 'POST',
-        body: JSON.stringify({ email, code })
-      });
+      await api.verify(email, code);
 
       setMessage({ text: '¡Cuenta verificada con éxito! Redirigiendo...', isError: false });
 
@@ -53,12 +58,38 @@ const Verify = () => {
       }, 2000);
 
     } catch (err) {
+      if (err.code === 'ALREADY_VERIFIED') {
+        localStorage.removeItem('pendingEmail');
+        localStorage.removeItem('pendingRole');
+        setMessage({ text: 'Tu cuenta ya estaba verificada. Inicia sesión.', isError: false });
+        setTimeout(() => navigate('/login'), 2000);
+        return;
+      }
+      // Caducado o bloqueado por intentos: solo sirve un código nuevo
+      if (err.code === 'CODE_EXPIRED' || err.code === 'TOO_MANY_ATTEMPTS') {
+        setCode('');
+      }
       setMessage({ text: err.message || 'Código incorrecto o expirado.', isError: true });
     } finally {
       setLoading(false);
     }
   };
 
+  const handleResend = async () => {
+    setMessage({ text: '', isError: false });
+    try {
+      await api.resendVerification(email);
+      setCode('');
+      setResendIn(60);
+      setMessage({ text: 'Te hemos enviado un código nuevo.', isError: false });
+    } catch (err) {
+      if (err.code === 'RESEND_TOO_SOON') {
+        setResendIn(err.retryAfter || 60);
+      }
+      setMessage({ text: err.message || 'No se pudo reenviar el código.', isError: true });
+    }
+  };
+
   // NUEVA FUNCIÓN: Para evitar que el usuario se quede atrapado por un error de rol
   const handleCancel = () => {
     if (window.confirm("¿Deseas cancelar este registro? Tendrás que empezar de nuevo.")) {
@@ -96,6 +127,14 @@ const Verify = () => {
             {loading ? 'Verificando...' : 'Verificar Cuenta'}
           </button>
 
+          <button
+            onClick={handleResend}
+            disabled={!email || resendIn > 0}
+            className="w-full p-2 text-[#1cabb0] text-sm font-semibold hover:underline transition disabled:text-gray-400 disabled:no-underline"
+          >
+            {resendIn > 0 ? `Reenviar código (${resendIn}s)` : '¿No te ha llegado? Reenviar código'}
+          </button>
+
           <button 
             onClick={handleCancel}
             className="w-full p-2 text-gray-400 text-xs hover:text-red-500 hover:underline transition"
@@ -1,7 +1,45 @@
 const BASE_URL = 'http://localhost:8080/api';
 
+// Un único refresco en vuelo aunque fallen varias peticiones a la vez:
+// cada refresh token solo vale una vez y reutilizarlo revoca la sesión
+let refreshing = null;
+
+const refreshSession = () => {
+  const refreshToken = localStorage.getItem('refresh_token');
+  if (!refreshToken) return Promise.resolve(null);
+
+  if (!refreshing) {
+    refreshing = fetch(`${BASE_URL}/auth/refresh`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ refresh_token: refreshToken }),
+    })
+      .then(async (res) => {
+        if (!res.ok) {
+          // Otra pestaña (comparten localStorage) ha refrescado antes con el mismo
+          // token: usamos su par en vez de cerrar la sesión
+          if (localStorage.getItem('refresh_token') !== refreshToken) {
+            return localStorage.getItem('token');
+          }
+          localStorage.removeItem('token');
+          localStorage.removeItem('refresh_token');
+          return null;
+        }
+        const data = await res.json();
+        localStorage.setItem('token', data.access_token);
+        localStorage.setItem('refresh_token', data.refresh_token);
+        return data.access_token;
+      })
+      .catch(() => null)
+      .finally(() => { refreshing = null; });
+  }
+  return refreshing;
+};
+
 const api = {
-  async request(endpoint, methodOrOptions = 'GET', body = null) {
+  refreshSession,
+
+  async request(endpoint, methodOrOptions = 'GET', body = null, retried = false) {
     let options = {};
     if (typeof methodOrOptions === 'object') {
       options = methodOrOptions;
@@ -25,6 +63,11 @@ const api = {
     try {
       const response = await fetch(urlCompleta, { ...options, headers });
 
+      // Access token caducado: se renueva con el refresh token y se reintenta una vez
+      if (response.status === 401 && token && !retried && await refreshSession()) {
+        return api.request(endpoint, methodOrOptions, body, true);
+      }
+
       if (response.status === 402) {
         const errorData = await response.json();
         throw { status: 402, message: errorData.error, payment_url: errorData.payment_url };
@@ -33,13 +76,20 @@ const api = {
       if (!response.ok) {
         const errorText = await response.text();
         let errorMessage = 'Error en la petición';
+        let errorCode;
         try {
           const errorData = JSON.parse(errorText);
           errorMessage = errorData.error || errorData.message || errorMessage;
+          errorCode = errorData.code;
         } catch {
           errorMessage = errorText || errorMessage;
         }
-        throw new Error(errorMessage);
+        // code (EMAIL_NOT_VERIFIED, CODE_EXPIRED...) permite reaccionar sin mirar el texto
+        const error = new Error(errorMessage);
+        error.status = response.status;
+        error.code = errorCode;
+        error.retryAfter = Number(response.headers.get('Retry-After')) || 0;
+        throw error;
       }
 
       const text = await response.text();
@@ -52,7 +102,9 @@ const api = {
 
   // --- AUTH ---
   login: (credentials) => api.request('/auth/login', 'POST', credentials),
-  verify: () => api.request('/auth/verify', 'POST'),
+  logout: (refreshToken) => api.request('/auth/logout', 'POST', { refresh_token: refreshToken }),
+  verify: (email, code) => api.request('/auth/verify', 'POST', { email, code }),
+  resendVerification: (email) => api.request('/auth/verify/resend', 'POST', { email }),
 
   // --- PERFILES (Doble vía: Vet y Público) ---
   getProfileDetails: (id) => {